@@ -0,0 +1,121 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/yendo/fcqs"
+	"github.com/yendo/fcqs/internal/value"
+	"golang.org/x/term"
+)
+
+var (
+	// stdin is the input of note bodies, which is replaced for test.
+	stdin = os.Stdin
+
+	// isTerminal reports whether the file descriptor is a terminal, which is replaced for test.
+	isTerminal = term.IsTerminal
+)
+
+// addNote adds the note with the title of --title to the notes file of --file or the notes file to add notes to.
+// The title must not be used in any notes files.
+func addNote() error {
+	title, err := value.NewTitle(*addTitle)
+	if err != nil {
+		return fmt.Errorf("title: %w", err)
+	}
+
+	if err := checkDuplicateTitle(title); err != nil {
+		return err
+	}
+
+	file, err := notesFileToAdd()
+	if err != nil {
+		return err
+	}
+
+	body, err := readNoteBody()
+	if err != nil {
+		return err
+	}
+
+	return fcqs.AddNote(file, title, body)
+}
+
+// notesFileToAdd returns the notes file of --file or the notes file to add notes to.
+func notesFileToAdd() (string, error) {
+	if *addFile != "" {
+		return *addFile, nil
+	}
+
+	return fcqs.NotesFileToAdd(config.Notes)
+}
+
+// checkDuplicateTitle returns an error if the title is used in the notes files.
+// Missing notes files are skipped since the file to add notes to may not exist yet.
+func checkDuplicateTitle(title *value.Title) error {
+	notes, err := fcqs.OpenExistingNotesFiles(config.Notes)
+	if err != nil {
+		return err
+	}
+	defer notes.Close()
+
+	locations, err := fcqs.NoteLocations(notes.Files, title)
+	if err != nil {
+		return err
+	}
+	if len(locations) > 0 {
+		return fmt.Errorf("%w: %s in %s:%d", fcqs.ErrDuplicateTitle, title, locations[0].File, locations[0].Line)
+	}
+
+	return nil
+}
+
+// readNoteBody returns the body of the note read from the standard input,
+// or written with the editor if the standard input is a terminal.
+func readNoteBody() (string, error) {
+	if isTerminal(int(stdin.Fd())) {
+		return editNoteBody()
+	}
+
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", fmt.Errorf("read note body: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// editNoteBody returns the body of the note written with the editor of VISUAL or EDITOR.
+func editNoteBody() (string, error) {
+	tmp, err := os.CreateTemp("", "fcqs-*.md")
+	if err != nil {
+		return "", fmt.Errorf("note body file: %w", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	tty, err := os.OpenFile(ttyFile, os.O_RDWR, 0)
+	if err != nil {
+		return "", fmt.Errorf("open terminal: %w", err)
+	}
+	defer tty.Close()
+
+	// The editor is run by the shell since it may have arguments like "code -w".
+	editor := cmp.Or(os.Getenv("VISUAL"), os.Getenv("EDITOR"), "vi")
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", tmp.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor: %w", err)
+	}
+
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return "", fmt.Errorf("read note body: %w", err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+	"github.com/yendo/fcqs"
+	"github.com/yendo/fcqs/internal/value"
+	"golang.org/x/term"
+)
+
+var ErrEmptyCommand = errors.New("empty command")
+
+// captureNote adds the command read from the standard input as a note with a command-line block of the shell.
+// The title is --title or asked on the terminal, and an empty answer cancels the note.
+func captureNote() error {
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return fmt.Errorf("read command: %w", err)
+	}
+	command := strings.Trim(string(data), "\r\n")
+	if strings.TrimSpace(command) == "" {
+		return ErrEmptyCommand
+	}
+
+	titleStr := *addTitle
+	if !flag.CommandLine.Changed("title") {
+		if titleStr, err = askTitle(command); err != nil {
+			return err
+		}
+		if strings.TrimSpace(titleStr) == "" {
+			return nil
+		}
+	}
+
+	title, err := value.NewTitle(titleStr)
+	if err != nil {
+		return fmt.Errorf("title: %w", err)
+	}
+
+	if err := checkDuplicateTitle(title); err != nil {
+		return err
+	}
+
+	file, err := notesFileToAdd()
+	if err != nil {
+		return err
+	}
+
+	return fcqs.AddNote(file, title, fcqs.FencedCodeBlock(command, captureLanguage(*shellName)))
+}
+
+// captureLanguage returns the fence language of a command captured in the shell.
+// Commands of bash are bash blocks, and those of zsh are generic sh blocks so that bash can also retrieve them.
+// fish and PowerShell have their own blocks since their syntax is not POSIX.
+func captureLanguage(shell string) string {
+	switch shell {
+	case "fish", "pwsh":
+		return shell
+	case "zsh":
+		return value.GenericShell
+	default:
+		return "bash"
+	}
+}
+
+// askTitle shows the command and asks the title of the note on the terminal.
+// The terminal is set to raw mode since key bindings of shells may run commands in any mode.
+func askTitle(command string) (string, error) {
+	tty, err := os.OpenFile(ttyFile, os.O_RDWR, 0)
+	if err != nil {
+		return "", fmt.Errorf("open terminal: %w", err)
+	}
+	defer tty.Close()
+
+	fd := int(tty.Fd())
+	if !isTerminal(fd) {
+		fmt.Fprintf(tty, "%s\nTitle: ", command)
+		answer, err := bufio.NewReader(tty).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("read title: %w", err)
+		}
+		return strings.TrimRight(answer, "\r\n"), nil
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return "", fmt.Errorf("terminal: %w", err)
+	}
+	defer term.Restore(fd, state) //nolint:errcheck
+
+	t := term.NewTerminal(tty, "Title: ")
+	fmt.Fprintln(t, command)
+	answer, err := t.ReadLine()
+	// Ctrl+C and Ctrl+D cancel the note.
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read title: %w", err)
+	}
+
+	return answer, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/yendo/fcqs"
+	"github.com/yendo/fcqs/internal/value"
+)
+
+// writeCmdLineBlock writes the command-line block specified by the options.
+func writeCmdLineBlock(w io.Writer, notes *fcqs.NotesFiles, arg string) error {
+	if *cmdIndex == 0 && !*allCmds && *shellName == "" {
+		return writeNote(w, notes, arg)
+	}
+
+	blocks, err := readCmdLineBlocks(notes, arg)
+	if err != nil {
+		return err
+	}
+
+	if *cmdIndex == 0 && !*allCmds {
+		if block, ok := fcqs.FirstCmdLineBlock(blocks, *shellName); ok {
+			fmt.Fprint(w, block.Command)
+		}
+		return nil
+	}
+
+	for i, block := range blocks {
+		if *allCmds || i+1 == *cmdIndex {
+			fmt.Fprint(w, block.Command)
+		}
+	}
+
+	return nil
+}
+
+// writeCmdLineBlockLabels writes the numbers and the labels of command-line blocks separated by tabs.
+func writeCmdLineBlockLabels(w io.Writer, notes *fcqs.NotesFiles, arg string) error {
+	blocks, err := readCmdLineBlocks(notes, arg)
+	if err != nil {
+		return err
+	}
+
+	for i, block := range blocks {
+		fmt.Fprintf(w, "%d\t%s\n", i+1, block.Label)
+	}
+
+	return nil
+}
+
+// readCmdLineBlocks returns the command-line blocks in the note for the shell specified by --shell.
+func readCmdLineBlocks(notes *fcqs.NotesFiles, arg string) ([]fcqs.CmdLineBlock, error) {
+	var buf bytes.Buffer
+	if err := writeContents(&buf, notes, arg, false); err != nil {
+		return nil, err
+	}
+
+	blocks, err := fcqs.CmdLineBlocks(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return fcqs.CmdLineBlocksForShell(blocks, *shellName), nil
+}
+
+// writeContents writes the contents of the note regardless of the output options.
+func writeContents(w io.Writer, notes *fcqs.NotesFiles, arg string, isNoTitle bool) error {
+	if *hierarchical {
+		path, err := value.NewTitlePath(arg)
+		if err != nil {
+			// This error should be ignored to omit argument checking in shell scripts.
+			return nil
+		}
+		return fcqs.WriteHierarchicalContents(w, notes.Reader, path, isNoTitle)
+	}
+
+	title, err := value.NewTitle(arg)
+	if err != nil {
+		// This error should be ignored to omit argument checking in shell scripts.
+		return nil
+	}
+
+	if !*noCache {
+		idx, err := fcqs.OpenIndex(notes.Files, fcqs.IndexCacheFile())
+		if err != nil {
+			return err
+		}
+		return idx.WriteContents(w, title, isNoTitle)
+	}
+
+	return fcqs.WriteContents(w, notes.Reader, title, isNoTitle)
+}
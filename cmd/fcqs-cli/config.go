@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	flag "github.com/spf13/pflag"
+	"github.com/yendo/fcqs"
+	"github.com/yendo/fcqs/internal/value"
+)
+
+// loadConfig loads the config file and applies the settings to the options not given on the command line.
+func loadConfig() error {
+	file := *configPath
+	if file == "" {
+		file = fcqs.ConfigFile()
+	} else if _, err := os.Stat(file); err != nil {
+		// The config file given on the command line must exist.
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	cfg, err := fcqs.LoadConfig(file)
+	if err != nil {
+		return err
+	}
+	config = cfg
+
+	if !flag.CommandLine.Changed("format") {
+		*format = cfg.Output.Format
+	}
+	if !flag.CommandLine.Changed("sort") {
+		*sortOrder = cfg.Output.Sort
+	}
+	if !flag.CommandLine.Changed("hierarchical") {
+		*hierarchical = cfg.Output.Hierarchical
+	}
+
+	for id, shell := range cfg.Fences.Shells {
+		value.AddShellLanguage(id, shell)
+	}
+
+	return nil
+}
+
+// jsonConfig represents the effective settings with the notes files of the project in JSON.
+type jsonConfig struct {
+	*fcqs.Config
+	ProjectFiles []string `json:"project_files"`
+}
+
+// writeConfig writes the effective settings, which are the config file overridden by environment variables and options.
+// The notes files of the project are written apart from the settings so that the output can be used as a config file.
+func writeConfig(w io.Writer) error {
+	cfg := *config
+
+	notes, err := cfg.Notes.Resolve()
+	if err != nil {
+		return err
+	}
+	cfg.Notes = notes
+	cfg.Output = fcqs.OutputConfig{Format: *format, Sort: *sortOrder, Hierarchical: *hierarchical}
+
+	projectFiles, err := cfg.Notes.ProjectFiles()
+	if err != nil {
+		return err
+	}
+
+	if *format == formatJSON {
+		return writeJSON(w, jsonConfig{Config: &cfg, ProjectFiles: jsonArray(projectFiles)})
+	}
+
+	if len(projectFiles) > 0 {
+		fmt.Fprintln(w, "# Project notes files used ahead of notes.files:")
+		for _, file := range projectFiles {
+			fmt.Fprintf(w, "#   %s\n", file)
+		}
+		fmt.Fprintln(w)
+	}
+
+	return fcqs.WriteConfig(w, &cfg)
+}
+
+// writeShellScript writes the settings in the config file and the integration script for the shell.
+func writeShellScript(w io.Writer, shell string) error {
+	if err := fcqs.WriteShellConfig(w, shell, config); err != nil {
+		return err
+	}
+
+	return fcqs.WriteShellScript(w, shell)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/yendo/fcqs"
+	"github.com/yendo/fcqs/internal/value"
+)
+
+var ErrNoMoveDestination = errors.New("no destination file: use --to")
+
+// editNotes rewrites the notes files by the rename, rm or mv subcommand,
+// or writes the diff of the changes with --dry-run.
+func editNotes(w io.Writer, args []string) error {
+	titles := make([]*value.Title, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		title, err := value.NewTitle(arg)
+		if err != nil {
+			return fmt.Errorf("title: %w", err)
+		}
+		titles = append(titles, title)
+	}
+
+	notes, err := fcqs.OpenNotesFilesWithConfig(config.Notes)
+	if err != nil {
+		return err
+	}
+	files := make([]string, 0, len(notes.Files))
+	for _, file := range notes.Files {
+		files = append(files, file.Name())
+	}
+	// The files are rewritten after they are closed.
+	notes.Close()
+
+	var changes []fcqs.FileChange
+	switch args[0] {
+	case "rename":
+		changes, err = fcqs.RenameNote(files, titles[0], titles[1])
+	case "rm":
+		changes, err = fcqs.RemoveNote(files, titles[0])
+	case "mv":
+		if *moveTo == "" {
+			return ErrNoMoveDestination
+		}
+		changes, err = fcqs.MoveNote(files, titles[0], *moveTo)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		return fcqs.WriteFileChangesDiff(w, changes)
+	}
+
+	return fcqs.ApplyFileChanges(changes)
+}
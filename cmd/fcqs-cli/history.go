@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/yendo/fcqs"
+	"github.com/yendo/fcqs/internal/value"
+)
+
+const (
+	sortByFile     = "file"
+	sortByFrecency = "frecency"
+)
+
+// recordTitle records the selected title in the history.
+func recordTitle(title string) error {
+	if _, err := value.NewTitle(title); err != nil {
+		// This error should be ignored to omit argument checking in shell scripts.
+		return nil
+	}
+
+	file, err := fcqs.HistoryFile()
+	if err != nil {
+		return err
+	}
+
+	return fcqs.RecordHistory(file, title, time.Now())
+}
+
+// writeSortedTitles writes the titles in the sort order, which are prefixed with their sources with --source.
+func writeSortedTitles(w io.Writer, notes *fcqs.NotesFiles) error {
+	var buf bytes.Buffer
+	if err := writeTitles(&buf, notes); err != nil {
+		return err
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+
+	titles := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if err := sortTitles(titles, notes); err != nil {
+		return err
+	}
+
+	var projects map[string]bool
+	if *showSource {
+		var err error
+		if projects, err = projectTitles(notes); err != nil {
+			return err
+		}
+	}
+
+	for _, title := range titles {
+		if *showSource {
+			fmt.Fprintf(w, "%s\t", titleSource(title, projects))
+		}
+		fmt.Fprintln(w, title)
+	}
+
+	return nil
+}
+
+// sortTitles sorts the titles in the order specified by the sort option.
+// The titles of the project notes are kept ahead of the others as well as in the notes files.
+func sortTitles(titles []string, notes *fcqs.NotesFiles) error {
+	if *sortOrder != sortByFrecency {
+		return nil
+	}
+
+	file, err := fcqs.HistoryFile()
+	if err != nil {
+		return err
+	}
+
+	frecency, err := fcqs.ReadFrecency(file, time.Now())
+	if err != nil {
+		return err
+	}
+	frecency.Sort(titles)
+
+	projects, err := projectTitles(notes)
+	if err != nil {
+		return err
+	}
+	sortProjectTitles(titles, projects)
+
+	return nil
+}
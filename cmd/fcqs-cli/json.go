@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yendo/fcqs"
+	"github.com/yendo/fcqs/internal/value"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// jsonNote represents a note in JSON.
+type jsonNote struct {
+	Title string `json:"title"`
+	fcqs.Location
+	Body       string           `json:"body"`
+	CodeBlocks []fcqs.CodeBlock `json:"code_blocks"`
+	URLs       []fcqs.Link      `json:"urls"`
+}
+
+// writeJSON writes the value as a line of JSON.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encode JSON: %w", err)
+	}
+
+	return nil
+}
+
+// jsonArray returns the slice, or an empty slice if it is nil to be encoded as an empty array.
+func jsonArray[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+
+	return s
+}
+
+// nth returns the pointer to the n-th element starting from 1, or nil to be encoded as null.
+func nth[T any](s []T, n int) *T {
+	if n < 1 || n > len(s) {
+		return nil
+	}
+
+	return &s[n-1]
+}
+
+// writeJSONList writes the titles of the notes with the locations, or the results of grep or search in JSON.
+func writeJSONList(w io.Writer, notes *fcqs.NotesFiles) error {
+	switch {
+	case *grepPattern != "":
+		pattern, err := fcqs.NewGrepPattern(*grepPattern, *isRegexp)
+		if err != nil {
+			return err
+		}
+		results, err := fcqs.GrepNotes(notes.Files, pattern, *hierarchical)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, jsonArray(results))
+	case *searchQuery != "":
+		results, err := fcqs.SearchNotes(notes.Reader, *searchQuery, *hierarchical)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, jsonArray(results))
+	default:
+		return writeJSONTitles(w, notes)
+	}
+}
+
+// writeJSONTitles writes the titles of the notes with the locations of all their title lines in JSON.
+func writeJSONTitles(w io.Writer, notes *fcqs.NotesFiles) error {
+	var buf bytes.Buffer
+	var err error
+	if *hierarchical {
+		err = fcqs.WriteHierarchicalTitles(&buf, notes.Reader, false)
+	} else {
+		err = writeTitles(&buf, notes)
+	}
+	if err != nil {
+		return err
+	}
+
+	var titles []string
+	if buf.Len() > 0 {
+		titles = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	}
+	if err := sortTitles(titles, notes); err != nil {
+		return err
+	}
+
+	if err := rewindNotesFiles(notes); err != nil {
+		return err
+	}
+	all, err := fcqs.TitleLocations(notes.Files, *hierarchical)
+	if err != nil {
+		return err
+	}
+
+	locations := make(map[string][]fcqs.Location, len(titles))
+	for _, tl := range all {
+		locations[tl.Title] = append(locations[tl.Title], tl.Location)
+	}
+
+	results := make([]fcqs.TitleLocation, 0, len(all))
+	for _, title := range titles {
+		// The text before the first title line is listed as an empty title, but has no title line.
+		if title == "" {
+			continue
+		}
+		for _, location := range locations[title] {
+			results = append(results, fcqs.TitleLocation{Title: title, Location: location})
+		}
+	}
+
+	return writeJSON(w, results)
+}
+
+// writeJSONNote writes the note, or the part of the note specified by the options in JSON.
+func writeJSONNote(w io.Writer, notes *fcqs.NotesFiles, arg string) error {
+	switch {
+	case *showCmd || *cmdIndex > 0 || *allCmds || *listCmds:
+		blocks, err := readCmdLineBlocks(notes, arg)
+		if err != nil {
+			return err
+		}
+		for i := range blocks {
+			if len(*vars) > 0 {
+				blocks[i].Command = fcqs.ExpandPlaceholders(blocks[i].Command, *vars)
+			}
+		}
+		if *allCmds || *listCmds {
+			return writeJSON(w, jsonArray(blocks))
+		}
+		if *cmdIndex == 0 {
+			if block, ok := fcqs.FirstCmdLineBlock(blocks, *shellName); ok {
+				return writeJSON(w, block)
+			}
+			return writeJSON(w, nil)
+		}
+		return writeJSON(w, nth(blocks, *cmdIndex))
+	case *showURL || *urlIndex > 0 || *listURLs:
+		links, err := readLinks(notes, arg)
+		if err != nil {
+			return err
+		}
+		if *listURLs {
+			return writeJSON(w, jsonArray(links))
+		}
+		return writeJSON(w, nth(links, max(*urlIndex, 1)))
+	case *showLoc:
+		locations, err := noteLocations(notes, arg)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, jsonArray(locations))
+	}
+
+	var buf bytes.Buffer
+	if err := writeContents(&buf, notes, arg, true); err != nil {
+		return err
+	}
+	if err := rewindNotesFiles(notes); err != nil {
+		return err
+	}
+	locations, err := noteLocations(notes, arg)
+	if err != nil {
+		return err
+	}
+	if len(locations) == 0 {
+		return writeJSON(w, nil)
+	}
+
+	body := buf.String()
+	codeBlocks, err := fcqs.CodeBlocks(strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	links, err := fcqs.Links(strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, jsonNote{
+		Title:      arg,
+		Location:   locations[0],
+		Body:       body,
+		CodeBlocks: jsonArray(codeBlocks),
+		URLs:       jsonArray(links),
+	})
+}
+
+// noteLocations returns the locations of the note in the notes files.
+func noteLocations(notes *fcqs.NotesFiles, arg string) ([]fcqs.Location, error) {
+	if *hierarchical {
+		path, err := value.NewTitlePath(arg)
+		if err != nil {
+			// This error should be ignored to omit argument checking in shell scripts.
+			return nil, nil
+		}
+		return fcqs.HierarchicalNoteLocations(notes.Files, path)
+	}
+
+	title, err := value.NewTitle(arg)
+	if err != nil {
+		// This error should be ignored to omit argument checking in shell scripts.
+		return nil, nil
+	}
+
+	if !*noCache {
+		idx, err := fcqs.OpenIndex(notes.Files, fcqs.IndexCacheFile())
+		if err != nil {
+			return nil, err
+		}
+		return idx.NoteLocations(title), nil
+	}
+
+	return fcqs.NoteLocations(notes.Files, title)
+}
+
+// rewindNotesFiles seeks the notes files to the beginning to scan them again.
+func rewindNotesFiles(notes *fcqs.NotesFiles) error {
+	for _, file := range notes.Files {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("rewind notes file: %w", err)
+		}
+	}
+
+	return nil
+}
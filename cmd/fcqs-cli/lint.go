@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/yendo/fcqs"
+)
+
+var ErrLintProblems = errors.New("problems found in notes files")
+
+// lintNotes writes the problems in the notes files, and returns an error if there are any problems.
+func lintNotes(w io.Writer) error {
+	notes, err := fcqs.OpenNotesFilesWithConfig(config.Notes)
+	if err != nil {
+		return err
+	}
+	defer notes.Close()
+
+	problems, err := fcqs.LintNotes(notes.Files)
+	if err != nil {
+		return err
+	}
+
+	if *format == formatJSON {
+		if err := writeJSON(w, jsonArray(problems)); err != nil {
+			return err
+		}
+	} else {
+		for _, p := range problems {
+			fmt.Fprintln(w, p)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %d", ErrLintProblems, len(problems))
+	}
+
+	return nil
+}
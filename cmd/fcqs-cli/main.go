@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 
 	flag "github.com/spf13/pflag"
 	"github.com/yendo/fcqs"
@@ -19,26 +20,142 @@ var (
 	showCmd     = flag.BoolP("command", "c", false, "output the first command from the note")
 	showLoc     = flag.BoolP("location", "l", false, "output the note location")
 	showBash    = flag.BoolP("bash", "", false, "output bash integration script")
+	showZsh     = flag.BoolP("zsh", "", false, "output zsh integration script")
+	showFish    = flag.BoolP("fish", "", false, "output fish integration script")
+	showPwsh    = flag.BoolP("pwsh", "", false, "output PowerShell integration script")
 	noTitle     = flag.BoolP("notitle", "t", false, "no title on output content")
 
+	hierarchical = flag.BoolP("hierarchical", "", false, "treat sub headings as parts of the note and titles as paths")
+	indent       = flag.BoolP("indent", "", false, "output indented titles instead of title paths with --hierarchical")
+	noCache      = flag.BoolP("no-cache", "", false, "scan the notes files without the index cache")
+	selectMode   = flag.BoolP("select", "", false, "select a title with the built-in fuzzy finder")
+	grepPattern  = flag.StringP("grep", "g", "", "output titles, line numbers and lines of note bodies matching the pattern")
+	isRegexp     = flag.BoolP("regexp", "E", false, "treat the pattern of --grep as a regular expression")
+	searchQuery  = flag.StringP("search", "s", "", "output titles of notes relevant to the query in order of relevance")
+	sortOrder    = flag.StringP("sort", "", sortByFile, "order of titles: file or frecency")
+	record       = flag.BoolP("record", "", false, "record the title as selected for frecency")
+	vars         = flag.StringToStringP("vars", "", nil, "values of placeholders in the command, e.g. key=value")
+	promptVars   = flag.BoolP("prompt", "", false, "prompt for values of placeholders in the command on the terminal")
+	cmdIndex     = flag.IntP("command-index", "", 0, "output the N-th command from the note, starting from 1")
+	allCmds      = flag.BoolP("all-commands", "", false, "output all commands from the note")
+	listCmds     = flag.BoolP("list-commands", "", false, "output the numbers and labels of commands in the note")
+	listURLs     = flag.BoolP("urls", "", false, "output the numbers, texts and URLs in the note")
+	urlIndex     = flag.IntP("url-index", "", 0, "output the N-th URL from the note, starting from 1")
+	format       = flag.StringP("format", "", formatText, "output format: text or json")
+	shellName    = flag.StringP("shell", "", "", "use commands for the shell falling back to generic sh: bash, zsh, fish or pwsh")
+	configPath   = flag.StringP("config", "", "", "path of the config file")
+	showSource   = flag.BoolP("source", "", false, "prefix titles with the sources of the notes separated by a tab: project or global")
+	addTitle     = flag.StringP("title", "", "", "title of the note to add with the add or capture command")
+	addFile      = flag.StringP("file", "", "", "notes file to add the note to with the add or capture command")
+	moveTo       = flag.StringP("to", "", "", "notes file to move the note to with the mv command")
+	dryRun       = flag.BoolP("dry-run", "", false, "output the diff of the rename, rm or mv command without changing files")
+
+	// config is the settings loaded from the config file and environment variables.
+	config = fcqs.DefaultConfig()
+
 	ErrInvalidNumberOfArgs = errors.New("invalid number of arguments")
+	ErrInvalidSortOrder    = errors.New("invalid sort order")
+	ErrInvalidCmdIndex     = errors.New("invalid command index")
+	ErrInvalidURLIndex     = errors.New("invalid URL index")
+	ErrInvalidFormat       = errors.New("invalid format")
 )
 
 func run(w io.Writer) error {
 	flag.Parse()
 	args := flag.Args()
 
+	if err := loadConfig(); err != nil {
+		return err
+	}
+
+	if *format != formatText && *format != formatJSON {
+		return fmt.Errorf("%w: %s", ErrInvalidFormat, *format)
+	}
+
 	if *showVersion {
+		if *format == formatJSON {
+			return writeJSON(w, map[string]string{"version": version})
+		}
 		fmt.Fprintln(w, version)
 		return nil
 	}
 
-	if *showBash {
-		fcqs.WriteBashScript(w)
-		return nil
+	switch {
+	case *showBash:
+		return writeShellScript(w, "bash")
+	case *showZsh:
+		return writeShellScript(w, "zsh")
+	case *showFish:
+		return writeShellScript(w, "fish")
+	case *showPwsh:
+		return writeShellScript(w, "pwsh")
+	}
+
+	if *sortOrder != sortByFile && *sortOrder != sortByFrecency {
+		return fmt.Errorf("%w: %s", ErrInvalidSortOrder, *sortOrder)
+	}
+
+	if *cmdIndex < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidCmdIndex, *cmdIndex)
+	}
+
+	if *urlIndex < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidURLIndex, *urlIndex)
+	}
+
+	if *shellName != "" && !slices.Contains(fcqs.Shells, *shellName) {
+		return fmt.Errorf("%w: %s", fcqs.ErrUnsupportedShell, *shellName)
+	}
+
+	if len(args) == 2 && args[0] == "config" && args[1] == "show" {
+		return writeConfig(w)
 	}
 
-	notes, err := fcqs.OpenNotesFiles()
+	switch subcommand(args) {
+	case "capture":
+		// A note titled "capture" is still available on a terminal without --shell,
+		// since the shell integrations pass --shell and pipe the command.
+		if flag.CommandLine.Changed("shell") || !isTerminal(int(stdin.Fd())) {
+			if len(args) != 1 {
+				return ErrInvalidNumberOfArgs
+			}
+			return captureNote()
+		}
+	case "lint":
+		if len(args) != 1 {
+			return ErrInvalidNumberOfArgs
+		}
+		return lintNotes(w)
+	case "rename":
+		// The names of subcommands with other numbers of arguments are titles.
+		if len(args) == 3 {
+			return editNotes(w, args)
+		}
+	case "rm", "mv":
+		if len(args) == 2 {
+			return editNotes(w, args)
+		}
+	case "add":
+		// A note titled "add" is still available without --title.
+		if flag.CommandLine.Changed("title") {
+			if len(args) != 1 {
+				return ErrInvalidNumberOfArgs
+			}
+			return addNote()
+		}
+	}
+	if flag.CommandLine.Changed("title") {
+		return ErrInvalidNumberOfArgs
+	}
+
+	if *record {
+		if len(args) != 1 {
+			return ErrInvalidNumberOfArgs
+		}
+		return recordTitle(args[0])
+	}
+
+	notes, err := fcqs.OpenNotesFilesWithConfig(config.Notes)
 	if err != nil {
 		return err
 	}
@@ -46,32 +163,165 @@ func run(w io.Writer) error {
 
 	switch len(args) {
 	case 0:
-		if *showURL || *showCmd || *showLoc {
+		if *showURL || *showCmd || *showLoc || *cmdIndex > 0 || *allCmds || *listCmds || *listURLs || *urlIndex > 0 {
 			return ErrInvalidNumberOfArgs
 		}
-		return fcqs.WriteTitles(w, notes.Reader)
+		if *selectMode {
+			return writeSelectedTitle(w, notes)
+		}
+		if *format == formatJSON {
+			return writeJSONList(w, notes)
+		}
+		if *grepPattern != "" {
+			return writeGrepResults(w, notes)
+		}
+		if *searchQuery != "" {
+			if *hierarchical {
+				return fcqs.WriteHierarchicalSearchResults(w, notes.Reader, *searchQuery)
+			}
+			return fcqs.WriteSearchResults(w, notes.Reader, *searchQuery)
+		}
+		if *sortOrder == sortByFrecency || *showSource {
+			return writeSortedTitles(w, notes)
+		}
+		return writeTitles(w, notes)
 	case 1:
-		title, err := value.NewTitle(args[0])
-		if err != nil {
-			// This error should be ignored to omit argument checking in shell scripts.
-			return nil
+		if *grepPattern != "" || *searchQuery != "" {
+			return ErrInvalidNumberOfArgs
 		}
-
-		switch {
-		case *showURL:
-			return fcqs.WriteFirstURL(w, notes.Reader, title)
-		case *showCmd:
-			return fcqs.WriteFirstCmdLineBlock(w, notes.Reader, title)
-		case *showLoc:
-			return fcqs.WriteNoteLocation(w, notes.Files, title)
-		default:
-			return fcqs.WriteContents(w, notes.Reader, title, *noTitle)
+		if *format == formatJSON && !*selectMode {
+			return writeJSONNote(w, notes, args[0])
+		}
+		if *selectMode {
+			if *listURLs {
+				return writeSelectedURL(w, notes, args[0])
+			}
+			return writeSelectedCmdLineBlockIndex(w, notes, args[0])
 		}
+		if *listCmds {
+			return writeCmdLineBlockLabels(w, notes, args[0])
+		}
+		if *listURLs {
+			return writeLinks(w, notes, args[0])
+		}
+		if *urlIndex > 0 {
+			return writeURL(w, notes, args[0])
+		}
+		if *showCmd || *cmdIndex > 0 || *allCmds {
+			if len(*vars) > 0 || *promptVars {
+				return writeExpandedCmdLineBlock(w, notes, args[0])
+			}
+			return writeCmdLineBlock(w, notes, args[0])
+		}
+		return writeNote(w, notes, args[0])
 	default:
 		return ErrInvalidNumberOfArgs
 	}
 }
 
+// subcommand returns the first argument as the name of a subcommand.
+// Arguments after "--" are titles of notes even if they are the names of subcommands.
+func subcommand(args []string) string {
+	if len(args) == 0 || flag.CommandLine.ArgsLenAtDash() == 0 {
+		return ""
+	}
+
+	return args[0]
+}
+
+func writeNote(w io.Writer, notes *fcqs.NotesFiles, arg string) error {
+	if *hierarchical {
+		return writeHierarchicalNote(w, notes, arg)
+	}
+
+	title, err := value.NewTitle(arg)
+	if err != nil {
+		// This error should be ignored to omit argument checking in shell scripts.
+		return nil
+	}
+
+	if !*noCache {
+		return writeIndexedNote(w, notes, title)
+	}
+
+	switch {
+	case *showURL:
+		return fcqs.WriteFirstURL(w, notes.Reader, title)
+	case *showCmd:
+		return fcqs.WriteFirstCmdLineBlock(w, notes.Reader, title, *shellName)
+	case *showLoc:
+		return fcqs.WriteNoteLocation(w, notes.Files, title)
+	default:
+		return fcqs.WriteContents(w, notes.Reader, title, *noTitle)
+	}
+}
+
+func writeTitles(w io.Writer, notes *fcqs.NotesFiles) error {
+	if *hierarchical {
+		return fcqs.WriteHierarchicalTitles(w, notes.Reader, *indent)
+	}
+
+	if !*noCache {
+		idx, err := fcqs.OpenIndex(notes.Files, fcqs.IndexCacheFile())
+		if err != nil {
+			return err
+		}
+		return idx.WriteTitles(w)
+	}
+
+	return fcqs.WriteTitles(w, notes.Reader)
+}
+
+func writeIndexedNote(w io.Writer, notes *fcqs.NotesFiles, title *value.Title) error {
+	idx, err := fcqs.OpenIndex(notes.Files, fcqs.IndexCacheFile())
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case *showURL:
+		return idx.WriteFirstURL(w, title)
+	case *showCmd:
+		return idx.WriteFirstCmdLineBlock(w, title)
+	case *showLoc:
+		return idx.WriteNoteLocation(w, title)
+	default:
+		return idx.WriteContents(w, title, *noTitle)
+	}
+}
+
+func writeGrepResults(w io.Writer, notes *fcqs.NotesFiles) error {
+	pattern, err := fcqs.NewGrepPattern(*grepPattern, *isRegexp)
+	if err != nil {
+		return err
+	}
+
+	if *hierarchical {
+		return fcqs.WriteHierarchicalGrepResults(w, notes.Files, pattern)
+	}
+
+	return fcqs.WriteGrepResults(w, notes.Files, pattern)
+}
+
+func writeHierarchicalNote(w io.Writer, notes *fcqs.NotesFiles, arg string) error {
+	path, err := value.NewTitlePath(arg)
+	if err != nil {
+		// This error should be ignored to omit argument checking in shell scripts.
+		return nil
+	}
+
+	switch {
+	case *showURL:
+		return fcqs.WriteHierarchicalFirstURL(w, notes.Reader, path)
+	case *showCmd:
+		return fcqs.WriteHierarchicalFirstCmdLineBlock(w, notes.Reader, path, *shellName)
+	case *showLoc:
+		return fcqs.WriteHierarchicalNoteLocation(w, notes.Files, path)
+	default:
+		return fcqs.WriteHierarchicalContents(w, notes.Reader, path, *noTitle)
+	}
+}
+
 func main() {
 	exitCode := 0
 
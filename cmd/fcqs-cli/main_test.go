@@ -2,17 +2,51 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	flag "github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/yendo/fcqs"
+	"github.com/yendo/fcqs/internal/finder"
 	"github.com/yendo/fcqs/test"
 )
 
+func TestMain(m *testing.M) {
+	// Keep the index cache of tests away from the user cache directory.
+	cacheDir, err := os.MkdirTemp("", "fcqs-cache")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CACHE_HOME", cacheDir)
+
+	// Keep the history of tests away from the user state directory.
+	stateDir, err := os.MkdirTemp("", "fcqs-state")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_STATE_HOME", stateDir)
+
+	// Keep the config file of the user away from tests.
+	configDir, err := os.MkdirTemp("", "fcqs-config")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", configDir)
+	os.Unsetenv("FCQS_CONFIG")
+
+	code := m.Run()
+	os.RemoveAll(cacheDir)
+	os.RemoveAll(stateDir)
+	os.RemoveAll(configDir)
+	os.Exit(code)
+}
+
 func setCommandLineFlag(t *testing.T, f string) {
 	t.Helper()
 
@@ -22,6 +56,21 @@ func setCommandLineFlag(t *testing.T, f string) {
 	t.Cleanup(func() {
 		err := flag.CommandLine.Set(f, "false")
 		require.NoError(t, err)
+		flag.CommandLine.Lookup(f).Changed = false
+	})
+}
+
+func setCommandLineString(t *testing.T, f, v string) {
+	t.Helper()
+
+	oldValue := flag.CommandLine.Lookup(f).Value.String()
+	err := flag.CommandLine.Set(f, v)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		err := flag.CommandLine.Set(f, oldValue)
+		require.NoError(t, err)
+		flag.CommandLine.Lookup(f).Changed = false
 	})
 }
 
@@ -33,6 +82,8 @@ func setOSArgs(t *testing.T, args []string) {
 
 	t.Cleanup(func() {
 		os.Args = oldArgs
+		// Init resets the position of "--" that Parse keeps.
+		flag.CommandLine.Init(oldArgs[0], flag.ExitOnError)
 	})
 }
 
@@ -71,6 +122,17 @@ func TestRunSuccess(t *testing.T) {
 		assert.Equal(t, "contents\n", buf.String())
 	})
 
+	t.Run("without the index cache", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--no-cache", "title"})
+		setCommandLineFlag(t, "no-cache")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "# title\n\ncontents\n", buf.String())
+	})
+
 	t.Run("with an empty arg and some option", func(t *testing.T) {
 		setOSArgs(t, []string{"fcqs-cli", "-u", ""})
 		setCommandLineFlag(t, "url")
@@ -199,6 +261,91 @@ func TestRunWithURLFlag(t *testing.T) {
 	})
 }
 
+func TestRunWithURLsFlags(t *testing.T) {
+	t.Setenv("FCQS_NOTES_FILE", test.NotesFile)
+	t.Setenv("FCQS_NOTES_FILES", "")
+
+	setURLIndex := func(t *testing.T, n string) {
+		t.Helper()
+
+		err := flag.CommandLine.Set("url-index", n)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			err := flag.CommandLine.Set("url-index", "0")
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("list URLs", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--urls", "URL"})
+		setCommandLineFlag(t, "urls")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "1\tfcqs\thttp://github.com/yendo/fcqs/\n2\tgithub\thttp://github.com/\n", buf.String())
+	})
+
+	t.Run("URL index", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--url-index", "2", "URL"})
+		setURLIndex(t, "2")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "http://github.com/\n", buf.String())
+	})
+
+	t.Run("URL index out of range", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--url-index", "3", "URL"})
+		setURLIndex(t, "3")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("select the only URL", func(t *testing.T) {
+		t.Setenv("FCQS_NOTES_FILE", test.HierarchyFile)
+		setOSArgs(t, []string{"fcqs-cli", "--select", "--urls", "grandchild"})
+		setCommandLineFlag(t, "select")
+		setCommandLineFlag(t, "urls")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.com/yendo/fcqs/\n", buf.String())
+	})
+
+	t.Run("invalid URL index", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--url-index", "-1", "URL"})
+		setURLIndex(t, "-1")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.EqualError(t, err, "invalid URL index: -1")
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("without args", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--urls"})
+		setCommandLineFlag(t, "urls")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, ErrInvalidNumberOfArgs)
+		assert.Empty(t, buf.String())
+	})
+}
+
 func TestRunWithCmdFlag(t *testing.T) {
 	t.Setenv("FCQS_NOTES_FILE", test.NotesFile)
 	t.Setenv("FCQS_NOTES_FILES", "")
@@ -264,6 +411,89 @@ func TestRunWithLocationFlag(t *testing.T) {
 	})
 }
 
+func TestRunWithHierarchicalFlag(t *testing.T) {
+	testFileName := test.HierarchyFile
+	t.Setenv("FCQS_NOTES_FILE", testFileName)
+	t.Setenv("FCQS_NOTES_FILES", "")
+	setCommandLineFlag(t, "hierarchical")
+
+	t.Run("show title paths", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--hierarchical"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "parent\nparent/child\nparent/child/grandchild\nparent/other child\n"+
+			"TCP\\/IP\nTCP\\/IP/child\nsections only\nsections only/section\n", buf.String())
+	})
+
+	t.Run("show indented titles", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--hierarchical", "--indent"})
+		setCommandLineFlag(t, "indent")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "parent\n  child\n    grandchild\n  other child\n"+
+			"TCP/IP\n  child\nsections only\n  section\n", buf.String())
+	})
+
+	t.Run("show contents", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--hierarchical", "TCP\\/IP/child"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "## child\n\nTCP/IP child contents\n", buf.String())
+	})
+
+	t.Run("show url", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--hierarchical", "-u", "parent/child"})
+		setCommandLineFlag(t, "url")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.com/yendo/fcqs/\n", buf.String())
+	})
+
+	t.Run("show command", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--hierarchical", "-c", "parent"})
+		setCommandLineFlag(t, "command")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "ls -l | nl\n", buf.String())
+	})
+
+	t.Run("show location", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--hierarchical", "-l", "parent/other child"})
+		setCommandLineFlag(t, "location")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("%q 17\n", testFileName), buf.String())
+	})
+
+	t.Run("with an empty title in the path", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--hierarchical", "parent//child"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Empty(t, buf.String())
+	})
+}
+
 func TestRunWithVersionFlag(t *testing.T) {
 	t.Parallel()
 
@@ -277,17 +507,1441 @@ func TestRunWithVersionFlag(t *testing.T) {
 	assert.Equal(t, version+"\n", buf.String())
 }
 
-func TestRunWithBashScriptFlag(t *testing.T) {
-	setCommandLineFlag(t, "bash")
-	setOSArgs(t, []string{"fcqs-cli", "--bash"})
+func TestRunWithShellScriptFlags(t *testing.T) {
+	tests := []struct {
+		shell    string
+		fileName string
+	}{
+		{shell: "bash", fileName: "../../shell.bash"},
+		{shell: "zsh", fileName: "../../shell.zsh"},
+		{shell: "fish", fileName: "../../shell.fish"},
+		{shell: "pwsh", fileName: "../../shell.ps1"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.shell, func(t *testing.T) {
+			setCommandLineFlag(t, tc.shell)
+			setOSArgs(t, []string{"fcqs-cli", "--" + tc.shell})
+
+			expectedData, err := os.ReadFile(tc.fileName)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = run(&buf)
+
+			require.NoError(t, err)
+			assert.Equal(t, string(expectedData), buf.String())
+		})
+	}
+}
+
+func TestRunWithSelectFlag(t *testing.T) {
+	t.Setenv("FCQS_NOTES_FILE", test.NotesFile)
+	t.Setenv("FCQS_NOTES_FILES", "")
+	setCommandLineFlag(t, "select")
+
+	tty := filepath.Join(t.TempDir(), "tty")
+	require.NoError(t, os.WriteFile(tty, nil, 0o600))
+	oldTTYFile := ttyFile
+	ttyFile = tty
+	t.Cleanup(func() { ttyFile = oldTTYFile })
+
+	t.Run("not a terminal", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--select"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, finder.ErrNoTerminal)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("no titles", func(t *testing.T) {
+		empty := filepath.Join(t.TempDir(), "empty.md")
+		require.NoError(t, os.WriteFile(empty, nil, 0o600))
+		t.Setenv("FCQS_NOTES_FILE", empty)
+		setOSArgs(t, []string{"fcqs-cli", "--select"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("no terminal", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--select"})
+		ttyFile = filepath.Join(t.TempDir(), "no_exist")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorContains(t, err, "open terminal: ")
+		assert.Empty(t, buf.String())
+		ttyFile = tty
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--select"})
+		t.Setenv("FCQS_COPY_KEY", "ctrl-yy")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, finder.ErrUnknownKey)
+		require.EqualError(t, err, "FCQS_COPY_KEY: unknown key: ctrl-yy")
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("select a command", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--select", "title"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, finder.ErrNoTerminal)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("with two args", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--select", "title", "other"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, ErrInvalidNumberOfArgs)
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestFinderActions(t *testing.T) {
+	t.Setenv("FCQS_COPY_KEY", "")
+	t.Setenv("FCQS_COPY_COMMAND", "pbcopy")
+	t.Setenv("FCQS_COPY_WITH_TITLE", "false")
+	t.Setenv("FCQS_OPEN_COMMAND", "")
+	t.Setenv("FCQS_EDIT_COMMAND", "")
+	t.Setenv("FCQS_EDITOR", "vscode")
 
-	fileName := "../../shell.bash"
-	expectedData, err := os.ReadFile(fileName)
+	exe, err := os.Executable()
 	require.NoError(t, err)
+	cli := finder.ShellQuote(exe) + " --format text"
 
-	var buf bytes.Buffer
-	err = run(&buf)
+	cfg, err := fcqs.LoadConfig("")
+	require.NoError(t, err)
+
+	actions, err := finderActions(cfg)
 
 	require.NoError(t, err)
-	assert.Equal(t, string(expectedData), buf.String())
+	assert.Equal(t, []finder.Action{
+		{Key: "ctrl-y", Command: cli + " -t -- {} | pbcopy"},
+		{Key: "ctrl-o", Command: cli + " --select --urls -- {} | xargs open", Interactive: true},
+		{Key: "ctrl-e", Command: cli + ` -l -- {} | awk '{printf "%s:%s\n",$1,$2}' | xargs -o code -g`, Abort: true},
+	}, actions)
+}
+
+func TestRunWithGrepFlag(t *testing.T) {
+	t.Setenv("FCQS_NOTES_FILE", test.GrepFile)
+	t.Setenv("FCQS_NOTES_FILES", "")
+
+	t.Run("literal", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--grep", "rules"})
+		setCommandLineString(t, "grep", "rules")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "sub note\t23\tiptables-save > rules\n", buf.String())
+	})
+
+	t.Run("regexp with hierarchical", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--hierarchical", "-E", "--grep", "^ipt.*rules$"})
+		setCommandLineString(t, "grep", "^ipt.*rules$")
+		setCommandLineFlag(t, "regexp")
+		setCommandLineFlag(t, "hierarchical")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "other note/sub note\t23\tiptables-save > rules\n", buf.String())
+	})
+
+	t.Run("invalid regexp", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "-E", "--grep", "("})
+		setCommandLineString(t, "grep", "(")
+		setCommandLineFlag(t, "regexp")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorContains(t, err, "grep pattern: ")
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("with an arg", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--grep", "rules", "title"})
+		setCommandLineString(t, "grep", "rules")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, ErrInvalidNumberOfArgs)
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestRunWithSearchFlag(t *testing.T) {
+	t.Setenv("FCQS_NOTES_FILE", test.SearchFile)
+	t.Setenv("FCQS_NOTES_FILES", "")
+
+	t.Run("ranked titles", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--search", "iptables"})
+		setCommandLineString(t, "search", "iptables")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "iptables\nfirewall with docker\n", buf.String())
+	})
+
+	t.Run("ranked title paths", func(t *testing.T) {
+		t.Setenv("FCQS_NOTES_FILE", test.HierarchyFile)
+		setOSArgs(t, []string{"fcqs-cli", "--hierarchical", "--search", "grandchild"})
+		setCommandLineString(t, "search", "grandchild")
+		setCommandLineFlag(t, "hierarchical")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "parent/child/grandchild\n", buf.String())
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--search", "!"})
+		setCommandLineString(t, "search", "!")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, fcqs.ErrEmptyQuery)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("with an arg", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--search", "docker", "title"})
+		setCommandLineString(t, "search", "docker")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, ErrInvalidNumberOfArgs)
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestRunWithFrecency(t *testing.T) {
+	t.Setenv("FCQS_NOTES_FILE", test.HierarchyFile)
+	t.Setenv("FCQS_NOTES_FILES", "")
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	t.Run("sort by file", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--sort", "file"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "parent\nchild\ngrandchild\nother child\nsection\n", buf.String())
+	})
+
+	for _, title := range []string{"section", "other child", "section", " "} {
+		t.Run("record "+title, func(t *testing.T) {
+			setOSArgs(t, []string{"fcqs-cli", "--record", title})
+			setCommandLineFlag(t, "record")
+
+			var buf bytes.Buffer
+			err := run(&buf)
+
+			require.NoError(t, err)
+			assert.Empty(t, buf.String())
+		})
+	}
+
+	t.Run("sort by frecency", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--sort", "frecency"})
+		setCommandLineString(t, "sort", "frecency")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "section\nother child\nparent\nchild\ngrandchild\n", buf.String())
+	})
+
+	t.Run("record without args", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--record"})
+		setCommandLineFlag(t, "record")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, ErrInvalidNumberOfArgs)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("invalid sort order", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--sort", "title"})
+		setCommandLineString(t, "sort", "title")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.EqualError(t, err, "invalid sort order: title")
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestRunWithPlaceholders(t *testing.T) {
+	t.Setenv("FCQS_NOTES_FILE", test.ShellBlockFile)
+	t.Setenv("FCQS_NOTES_FILES", "")
+	setCommandLineFlag(t, "command")
+
+	setVars := func(t *testing.T, v string) {
+		t.Helper()
+
+		err := flag.CommandLine.Set("vars", v)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			*vars = make(map[string]string)
+		})
+	}
+
+	setTTYFile := func(t *testing.T, input string) {
+		t.Helper()
+
+		tty := filepath.Join(t.TempDir(), "tty")
+		err := os.WriteFile(tty, []byte(input), 0o600)
+		require.NoError(t, err)
+
+		oldTTYFile := ttyFile
+		ttyFile = tty
+		t.Cleanup(func() { ttyFile = oldTTYFile })
+	}
+
+	t.Run("without vars", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "-c", "placeholders"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "kubectl logs {{pod}} -n {{namespace:default}}\n", buf.String())
+	})
+
+	t.Run("with vars", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "-c", "--vars", "pod=web-0", "placeholders"})
+		setVars(t, "pod=web-0")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "kubectl logs web-0 -n default\n", buf.String())
+	})
+
+	t.Run("with prompt and no input", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "-c", "--prompt", "placeholders"})
+		setCommandLineFlag(t, "prompt")
+		setTTYFile(t, "")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.EqualError(t, err, "read value of pod: EOF")
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("with prompt and no placeholders", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "-c", "--prompt", "tilde"})
+		setCommandLineFlag(t, "prompt")
+		ttyFile = filepath.Join(t.TempDir(), "no_exist")
+		t.Cleanup(func() { ttyFile = "/dev/tty" })
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "ls -l | nl\n", buf.String())
+	})
+}
+
+func TestAskPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	placeholders := []fcqs.Placeholder{
+		{Name: "pod"},
+		{Name: "namespace", Default: "default", HasDefault: true},
+		{Name: "container"},
+	}
+	values := map[string]string{}
+	var out bytes.Buffer
+
+	err := askPlaceholders(strings.NewReader("web-0\r\n\nnginx"), &out, placeholders, values)
+
+	require.NoError(t, err)
+	assert.Equal(t, "pod: namespace [default]: container: ", out.String())
+	assert.Equal(t, map[string]string{"pod": "web-0", "container": "nginx"}, values)
+}
+
+func TestRunWithCommandsFlags(t *testing.T) {
+	t.Setenv("FCQS_NOTES_FILE", test.ShellBlockFile)
+	t.Setenv("FCQS_NOTES_FILES", "")
+
+	setCmdIndex := func(t *testing.T, n string) {
+		t.Helper()
+
+		err := flag.CommandLine.Set("command-index", n)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			err := flag.CommandLine.Set("command-index", "0")
+			require.NoError(t, err)
+		})
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		flags  []string
+		index  string
+		shell  string
+		expect string
+	}{
+		{
+			name:   "first command",
+			args:   []string{"fcqs-cli", "-c", "steps"},
+			flags:  []string{"command"},
+			expect: "sudo apt install jq\n",
+		},
+		{
+			name:   "second command",
+			args:   []string{"fcqs-cli", "--command-index", "2", "steps"},
+			index:  "2",
+			expect: "jq --version\n",
+		},
+		{
+			name:   "command index out of range",
+			args:   []string{"fcqs-cli", "--command-index", "4", "steps"},
+			index:  "4",
+			expect: "",
+		},
+		{
+			name:   "all commands",
+			args:   []string{"fcqs-cli", "--all-commands", "steps"},
+			flags:  []string{"all-commands"},
+			expect: "sudo apt install jq\njq --version\njq . config.json\n",
+		},
+		{
+			name:   "all commands without cache",
+			args:   []string{"fcqs-cli", "--no-cache", "--all-commands", "steps"},
+			flags:  []string{"no-cache", "all-commands"},
+			expect: "sudo apt install jq\njq --version\njq . config.json\n",
+		},
+		{
+			name:   "list commands",
+			args:   []string{"fcqs-cli", "--list-commands", "steps"},
+			flags:  []string{"list-commands"},
+			expect: "1\tInstall the package:\n2\tjq --version\n3\tMore details.\n",
+		},
+		{
+			name:   "list commands of note without commands",
+			args:   []string{"fcqs-cli", "--list-commands", "go"},
+			flags:  []string{"list-commands"},
+			expect: "",
+		},
+		{
+			name:   "first command for bash",
+			args:   []string{"fcqs-cli", "-c", "--shell", "bash", "cross shell"},
+			flags:  []string{"command"},
+			shell:  "bash",
+			expect: "ls -a\n",
+		},
+		{
+			name:   "first command for pwsh",
+			args:   []string{"fcqs-cli", "-c", "--shell", "pwsh", "cross shell"},
+			flags:  []string{"command"},
+			shell:  "pwsh",
+			expect: "Get-ChildItem -Force\n",
+		},
+		{
+			name:   "first command for zsh falling back to sh",
+			args:   []string{"fcqs-cli", "-c", "--shell", "zsh", "cross shell"},
+			flags:  []string{"command"},
+			shell:  "zsh",
+			expect: "ls -A\n",
+		},
+		{
+			name:   "list commands for bash",
+			args:   []string{"fcqs-cli", "--list-commands", "--shell", "bash", "cross shell"},
+			flags:  []string{"list-commands"},
+			shell:  "bash",
+			expect: "1\tls -A\n2\tls -a\n",
+		},
+		{
+			name:   "list commands for pwsh",
+			args:   []string{"fcqs-cli", "--list-commands", "--shell", "pwsh", "cross shell"},
+			flags:  []string{"list-commands"},
+			shell:  "pwsh",
+			expect: "1\tcross shell\n",
+		},
+		{
+			name:   "command index with vars",
+			args:   []string{"fcqs-cli", "--command-index", "1", "--vars", "pod=web-0", "placeholders"},
+			index:  "1",
+			expect: "kubectl logs web-0 -n default\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			setOSArgs(t, tc.args)
+			for _, f := range tc.flags {
+				setCommandLineFlag(t, f)
+			}
+			if tc.index != "" {
+				setCmdIndex(t, tc.index)
+			}
+			if tc.shell != "" {
+				setCommandLineString(t, "shell", tc.shell)
+			}
+			t.Cleanup(func() { *vars = make(map[string]string) })
+
+			var buf bytes.Buffer
+			err := run(&buf)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, buf.String())
+		})
+	}
+
+	t.Run("invalid command index", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--command-index", "-1", "steps"})
+		setCmdIndex(t, "-1")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.EqualError(t, err, "invalid command index: -1")
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("unsupported shell", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "-c", "--shell", "csh", "steps"})
+		setCommandLineFlag(t, "command")
+		setCommandLineString(t, "shell", "csh")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.EqualError(t, err, "unsupported shell: csh")
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("without args", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--all-commands"})
+		setCommandLineFlag(t, "all-commands")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, ErrInvalidNumberOfArgs)
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestRunWithFormatFlag(t *testing.T) {
+	t.Setenv("FCQS_NOTES_FILE", test.NotesFile)
+	t.Setenv("FCQS_NOTES_FILES", "")
+
+	tests := []struct {
+		name   string
+		args   []string
+		flags  []string
+		grep   string
+		expect string
+	}{
+		{
+			name:   "note",
+			args:   []string{"fcqs-cli", "--format", "json", "URL"},
+			expect: `{"title":"URL","file":"` + test.NotesFile + `","line":76,"body":"fcqs: http://github.com/yendo/fcqs/\ngithub: http://github.com/\n","code_blocks":[],"urls":[{"text":"fcqs","url":"http://github.com/yendo/fcqs/"},{"text":"github","url":"http://github.com/"}]}` + "\n",
+		},
+		{
+			name:   "note without cache",
+			args:   []string{"fcqs-cli", "--no-cache", "--format", "json", "more command-line blocks"},
+			flags:  []string{"no-cache"},
+			expect: `{"title":"more command-line blocks","file":"` + test.NotesFile + `","line":93,"body":"` + "```sh\\nls -l | nl\\n```\\n\\n```console\\n$ date\\n```\\n" + `","code_blocks":[{"language":"sh","code":"ls -l | nl\n"},{"language":"console","code":"$ date\n"}],"urls":[]}` + "\n",
+		},
+		{
+			name:   "no note",
+			args:   []string{"fcqs-cli", "--format", "json", "no such note"},
+			expect: "null\n",
+		},
+		{
+			name:   "location",
+			args:   []string{"fcqs-cli", "--format", "json", "-l", "URL"},
+			flags:  []string{"location"},
+			expect: `[{"file":"` + test.NotesFile + `","line":76}]` + "\n",
+		},
+		{
+			name:   "first URL",
+			args:   []string{"fcqs-cli", "--format", "json", "-u", "URL"},
+			flags:  []string{"url"},
+			expect: `{"text":"fcqs","url":"http://github.com/yendo/fcqs/"}` + "\n",
+		},
+		{
+			name:   "first command",
+			args:   []string{"fcqs-cli", "--format", "json", "-c", "more command-line blocks"},
+			flags:  []string{"command"},
+			expect: `{"label":"more command-line blocks","command":"ls -l | nl\n","language":"sh"}` + "\n",
+		},
+		{
+			name:   "all commands",
+			args:   []string{"fcqs-cli", "--format", "json", "--all-commands", "more command-line blocks"},
+			flags:  []string{"all-commands"},
+			expect: `[{"label":"more command-line blocks","command":"ls -l | nl\n","language":"sh"},{"label":"date","command":"date\n","language":"sh"}]` + "\n",
+		},
+		{
+			name:   "no command",
+			args:   []string{"fcqs-cli", "--format", "json", "-c", "URL"},
+			flags:  []string{"command"},
+			expect: "null\n",
+		},
+		{
+			name:   "grep",
+			args:   []string{"fcqs-cli", "--format", "json", "--grep", "github:"},
+			grep:   "github:",
+			expect: `[{"title":"URL","file":"` + test.NotesFile + `","line":79,"text":"github: http://github.com/"}]` + "\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			setOSArgs(t, tc.args)
+			setCommandLineString(t, "format", formatJSON)
+			for _, f := range tc.flags {
+				setCommandLineFlag(t, f)
+			}
+			if tc.grep != "" {
+				setCommandLineString(t, "grep", tc.grep)
+			}
+
+			var buf bytes.Buffer
+			err := run(&buf)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, buf.String())
+		})
+	}
+
+	t.Run("titles", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--format", "json"})
+		setCommandLineString(t, "format", formatJSON)
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		var titles []fcqs.TitleLocation
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &titles))
+		assert.Equal(t, fcqs.TitleLocation{Title: "title", Location: fcqs.Location{File: test.NotesFile, Line: 1}}, titles[0])
+		assert.Contains(t, titles, fcqs.TitleLocation{Title: "URL", Location: fcqs.Location{File: test.NotesFile, Line: 76}})
+	})
+
+	t.Run("titles without text before the first title", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "notes.md")
+		require.NoError(t, os.WriteFile(file, []byte("preamble\n\n# title\ncontents\n"), 0o600))
+		t.Setenv("FCQS_NOTES_FILE", file)
+		setOSArgs(t, []string{"fcqs-cli", "--format", "json"})
+		setCommandLineString(t, "format", formatJSON)
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, `[{"title":"title","file":"`+file+`","line":3}]`+"\n", buf.String())
+	})
+
+	t.Run("titles in multiple files", func(t *testing.T) {
+		dir := t.TempDir()
+		project := filepath.Join(dir, "project.md")
+		global := filepath.Join(dir, "global.md")
+		require.NoError(t, os.WriteFile(project, []byte("# title\nproject\n"), 0o600))
+		require.NoError(t, os.WriteFile(global, []byte("# other\nglobal\n\n# title\nglobal\n"), 0o600))
+		t.Setenv("FCQS_NOTES_FILES", test.MultiFiles(project, global))
+		setOSArgs(t, []string{"fcqs-cli", "--format", "json"})
+		setCommandLineString(t, "format", formatJSON)
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, `[{"title":"title","file":"`+project+`","line":1},`+
+			`{"title":"title","file":"`+global+`","line":4},`+
+			`{"title":"other","file":"`+global+`","line":1}]`+"\n", buf.String())
+	})
+
+	t.Run("version", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--format", "json", "--version"})
+		setCommandLineString(t, "format", formatJSON)
+		setCommandLineFlag(t, "version")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, `{"version":"`+version+`"}`+"\n", buf.String())
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--format", "yaml"})
+		setCommandLineString(t, "format", "yaml")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.EqualError(t, err, "invalid format: yaml")
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestRunWithConfig(t *testing.T) {
+	t.Setenv("FCQS_NOTES_FILE", "")
+	t.Setenv("FCQS_NOTES_FILES", "")
+	t.Setenv("FCQS_NOTES_IGNORE", "")
+	t.Setenv("FCQS_SORT", "")
+	t.Setenv("FCQS_HIERARCHICAL", "")
+	t.Setenv("FCQS_COPY_KEY", "")
+
+	// The format loaded from the config file is reset for other tests.
+	t.Cleanup(func() {
+		*format = formatText
+	})
+
+	file := filepath.Join(t.TempDir(), "config.toml")
+	err := os.WriteFile(file, []byte(fmt.Sprintf(`
+[notes]
+files = [%q]
+
+[keys]
+copy = "ctrl-k"
+
+[output]
+format = "json"
+`, test.NotesFile)), 0o600)
+	require.NoError(t, err)
+
+	t.Run("config file from the command line", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--config", file, "-l", "URL"})
+		setCommandLineString(t, "config", file)
+		setCommandLineFlag(t, "location")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, `[{"file":"`+test.NotesFile+`","line":76}]`+"\n", buf.String())
+	})
+
+	t.Run("options take precedence", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--config", file, "--format", "text", "-l", "URL"})
+		setCommandLineString(t, "config", file)
+		setCommandLineString(t, "format", "text")
+		setCommandLineFlag(t, "location")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("%q 76\n", test.NotesFile), buf.String())
+	})
+
+	t.Run("config file from the environment variable", func(t *testing.T) {
+		t.Setenv("FCQS_CONFIG", file)
+		setOSArgs(t, []string{"fcqs-cli", "--format", "text", "config", "show"})
+		setCommandLineString(t, "format", "text")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), fmt.Sprintf("[notes]\n  files = [%q]\n", test.NotesFile))
+		assert.Contains(t, buf.String(), "[keys]\n  copy = \"ctrl-k\"\n  open = \"ctrl-o\"\n")
+		assert.Contains(t, buf.String(), "[output]\n  format = \"text\"\n  sort = \"file\"\n  hierarchical = false\n")
+	})
+
+	t.Run("show in JSON", func(t *testing.T) {
+		t.Setenv("FCQS_COPY_KEY", "ctrl-x")
+		setOSArgs(t, []string{"fcqs-cli", "--config", file, "config", "show"})
+		setCommandLineString(t, "config", file)
+
+		var buf bytes.Buffer
+		err := run(&buf)
+		require.NoError(t, err)
+
+		var cfg fcqs.Config
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &cfg))
+		assert.Equal(t, []string{test.NotesFile}, cfg.Notes.Files)
+		assert.Equal(t, "ctrl-x", cfg.Keys.Copy)
+		assert.Equal(t, "json", cfg.Output.Format)
+	})
+
+	t.Run("shell script with the settings", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--config", file, "--bash"})
+		setCommandLineString(t, "config", file)
+		setCommandLineFlag(t, "bash")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		script, err := os.ReadFile("../../shell.bash")
+		require.NoError(t, err)
+		assert.Equal(t, "export FCQS_CONFIG='"+file+"'\n"+
+			`[ -n "${FCQS_COPY_KEY}" ] || FCQS_COPY_KEY='ctrl-k'`+"\n"+string(script), buf.String())
+	})
+
+	t.Run("config file not found", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "config.toml")
+		setOSArgs(t, []string{"fcqs-cli", "--config", missing, "config", "show"})
+		setCommandLineString(t, "config", missing)
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, os.ErrNotExist)
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestRunWithProjectNotes(t *testing.T) {
+	t.Setenv("FCQS_NOTES_FILE", test.HierarchyFile)
+	t.Setenv("FCQS_NOTES_FILES", "")
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	repo := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(repo, ".git"), 0o700))
+	err := os.WriteFile(filepath.Join(repo, fcqs.DefaultNotesFile), []byte("# deploy\ncontents\n\n# section\nproject section\n"), 0o600)
+	require.NoError(t, err)
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(repo))
+	t.Cleanup(func() { os.Chdir(wd) }) //nolint:errcheck
+
+	t.Run("titles with sources", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--source"})
+		setCommandLineFlag(t, "source")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "project\tdeploy\nproject\tsection\nglobal\tparent\nglobal\tchild\nglobal\tgrandchild\nglobal\tother child\n", buf.String())
+	})
+
+	t.Run("record other child", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--record", "other child"})
+		setCommandLineFlag(t, "record")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("project notes first by frecency", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--sort", "frecency", "--source"})
+		setCommandLineString(t, "sort", "frecency")
+		setCommandLineFlag(t, "source")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "project\tdeploy\nproject\tsection\nglobal\tother child\nglobal\tparent\nglobal\tchild\nglobal\tgrandchild\n", buf.String())
+	})
+
+	t.Run("note in both files", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "section"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(buf.String(), "# section\nproject section\n"), buf.String())
+	})
+
+	t.Run("project notes apart from the config", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--format", "text", "config", "show"})
+		setCommandLineString(t, "format", "text")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		project := filepath.Join(repo, fcqs.DefaultNotesFile)
+		assert.True(t, strings.HasPrefix(buf.String(), "# Project notes files used ahead of notes.files:\n#   "+project+"\n\n"), buf.String())
+		assert.Contains(t, buf.String(), fmt.Sprintf("[notes]\n  files = [%q]\n", test.HierarchyFile))
+	})
+
+	t.Run("project notes apart from the config in JSON", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--format", "json", "config", "show"})
+		setCommandLineString(t, "format", "json")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+		require.NoError(t, err)
+
+		var cfg struct {
+			fcqs.Config
+			ProjectFiles []string `json:"project_files"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &cfg))
+		assert.Equal(t, []string{test.HierarchyFile}, cfg.Notes.Files)
+		assert.Equal(t, []string{filepath.Join(repo, fcqs.DefaultNotesFile)}, cfg.ProjectFiles)
+	})
+
+	t.Run("project notes disabled", func(t *testing.T) {
+		t.Setenv("FCQS_PROJECT_NOTES", "false")
+		setOSArgs(t, []string{"fcqs-cli"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "parent\nchild\ngrandchild\nother child\nsection\n", buf.String())
+	})
+}
+
+func TestRunWithAddCommand(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.md")
+	require.NoError(t, os.WriteFile(file, []byte("# first\ncontents\n"), 0o600))
+	t.Setenv("FCQS_NOTES_FILE", "")
+	t.Setenv("FCQS_NOTES_FILES", test.MultiFiles(file, test.NotesFile))
+
+	// setStdin replaces the standard input with a file of the contents.
+	setStdin := func(t *testing.T, contents string) {
+		t.Helper()
+
+		in := filepath.Join(t.TempDir(), "stdin")
+		require.NoError(t, os.WriteFile(in, []byte(contents), 0o600))
+		f, err := os.Open(in)
+		require.NoError(t, err)
+
+		oldStdin := stdin
+		stdin = f
+		t.Cleanup(func() {
+			stdin = oldStdin
+			f.Close()
+		})
+	}
+
+	t.Run("body from stdin", func(t *testing.T) {
+		setStdin(t, "added contents\n")
+		setOSArgs(t, []string{"fcqs-cli", "add", "--title", "added"})
+		setCommandLineString(t, "title", "added")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Empty(t, buf.String())
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "# first\ncontents\n\n# added\n\nadded contents\n", string(data))
+	})
+
+	t.Run("body from editor", func(t *testing.T) {
+		setStdin(t, "")
+		oldIsTerminal, oldTTYFile := isTerminal, ttyFile
+		isTerminal = func(int) bool { return true }
+		ttyFile = filepath.Join(t.TempDir(), "tty")
+		require.NoError(t, os.WriteFile(ttyFile, nil, 0o600))
+		t.Cleanup(func() { isTerminal, ttyFile = oldIsTerminal, oldTTYFile })
+		t.Setenv("VISUAL", `printf 'edited contents\n' >`)
+
+		other := filepath.Join(dir, "other.md")
+		setOSArgs(t, []string{"fcqs-cli", "add", "--title", "edited", "--file", other})
+		setCommandLineString(t, "title", "edited")
+		setCommandLineString(t, "file", other)
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		data, err := os.ReadFile(other)
+		require.NoError(t, err)
+		assert.Equal(t, "# edited\n\nedited contents\n", string(data))
+	})
+
+	t.Run("duplicate title in other file", func(t *testing.T) {
+		setStdin(t, "contents\n")
+		setOSArgs(t, []string{"fcqs-cli", "add", "--title", " URL "})
+		setCommandLineString(t, "title", " URL ")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, fcqs.ErrDuplicateTitle)
+		require.EqualError(t, err, "duplicate title: URL in "+test.NotesFile+":76")
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "URL")
+	})
+
+	t.Run("duplicate title with the new notes file", func(t *testing.T) {
+		newFile := filepath.Join(t.TempDir(), "new.md")
+		t.Setenv("FCQS_NOTES_FILES", test.MultiFiles(newFile, test.NotesFile))
+		setStdin(t, "contents\n")
+		setOSArgs(t, []string{"fcqs-cli", "add", "--title", "URL"})
+		setCommandLineString(t, "title", "URL")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, fcqs.ErrDuplicateTitle)
+		assert.NoFileExists(t, newFile)
+	})
+
+	t.Run("empty title", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "add", "--title", " "})
+		setCommandLineString(t, "title", " ")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.EqualError(t, err, "title: title is empty")
+	})
+
+	t.Run("title without add", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--title", "added"})
+		setCommandLineString(t, "title", "added")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, ErrInvalidNumberOfArgs)
+	})
+}
+
+func TestRunWithCaptureCommand(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.md")
+	t.Setenv("FCQS_NOTES_FILE", "")
+	t.Setenv("FCQS_NOTES_FILES", test.MultiFiles(file, test.NotesFile))
+
+	setStdin := func(t *testing.T, contents string) {
+		t.Helper()
+
+		in := filepath.Join(t.TempDir(), "stdin")
+		require.NoError(t, os.WriteFile(in, []byte(contents), 0o600))
+		f, err := os.Open(in)
+		require.NoError(t, err)
+
+		oldStdin := stdin
+		stdin = f
+		t.Cleanup(func() {
+			stdin = oldStdin
+			f.Close()
+		})
+	}
+
+	setTTYFile := func(t *testing.T, input string) {
+		t.Helper()
+
+		tty := filepath.Join(t.TempDir(), "tty")
+		require.NoError(t, os.WriteFile(tty, []byte(input), 0o600))
+
+		oldTTYFile := ttyFile
+		ttyFile = tty
+		t.Cleanup(func() { ttyFile = oldTTYFile })
+	}
+
+	t.Run("command with title", func(t *testing.T) {
+		setStdin(t, "ls -l\n")
+		setOSArgs(t, []string{"fcqs-cli", "capture", "--title", "list files"})
+		setCommandLineString(t, "title", "list files")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("title asked on the terminal", func(t *testing.T) {
+		setStdin(t, "git status --short\n")
+		// The prompt overwrites the head of the file before the answer is read.
+		prompt := "git status --short\nTitle: "
+		setTTYFile(t, strings.Repeat(" ", len(prompt))+"short status\n")
+		setOSArgs(t, []string{"fcqs-cli", "capture", "--shell", "zsh"})
+		setCommandLineString(t, "shell", "zsh")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "# list files\n\n```bash\nls -l\n```\n\n# short status\n\n```sh\ngit status --short\n```\n", string(data))
+	})
+
+	t.Run("command captured in zsh for bash", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "-c", "--shell", "bash", "short status"})
+		setCommandLineFlag(t, "command")
+		setCommandLineString(t, "shell", "bash")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "git status --short\n", buf.String())
+	})
+
+	t.Run("captured command", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "-c", "list files"})
+		setCommandLineFlag(t, "command")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "ls -l\n", buf.String())
+	})
+
+	t.Run("cancel with empty title", func(t *testing.T) {
+		setStdin(t, "pwd\n")
+		setTTYFile(t, "")
+		setOSArgs(t, []string{"fcqs-cli", "capture"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "pwd")
+	})
+
+	t.Run("duplicate title", func(t *testing.T) {
+		setStdin(t, "pwd\n")
+		setOSArgs(t, []string{"fcqs-cli", "capture", "--title", "URL"})
+		setCommandLineString(t, "title", "URL")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, fcqs.ErrDuplicateTitle)
+	})
+
+	t.Run("empty command", func(t *testing.T) {
+		setStdin(t, "\n")
+		setOSArgs(t, []string{"fcqs-cli", "capture", "--title", "empty"})
+		setCommandLineString(t, "title", "empty")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, ErrEmptyCommand)
+	})
+
+	t.Run("note titled capture on a terminal", func(t *testing.T) {
+		notes := filepath.Join(t.TempDir(), "notes.md")
+		require.NoError(t, os.WriteFile(notes, []byte("# capture\nnote contents\n"), 0o600))
+		t.Setenv("FCQS_NOTES_FILES", notes)
+		oldIsTerminal := isTerminal
+		isTerminal = func(int) bool { return true }
+		t.Cleanup(func() { isTerminal = oldIsTerminal })
+		setOSArgs(t, []string{"fcqs-cli", "capture"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "# capture\nnote contents\n", buf.String())
+	})
+
+	t.Run("capture on a terminal with shell", func(t *testing.T) {
+		setStdin(t, "uname -a\n")
+		oldIsTerminal := isTerminal
+		isTerminal = func(int) bool { return true }
+		t.Cleanup(func() { isTerminal = oldIsTerminal })
+		setOSArgs(t, []string{"fcqs-cli", "capture", "--shell", "bash", "--title", "kernel"})
+		setCommandLineString(t, "shell", "bash")
+		setCommandLineString(t, "title", "kernel")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "# kernel\n\n```bash\nuname -a\n```\n")
+	})
+
+	t.Run("note titled capture", func(t *testing.T) {
+		setOSArgs(t, []string{"fcqs-cli", "--", "capture"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestRunWithEditCommands(t *testing.T) {
+	const contents = "# first\nfirst contents\n\n# second\nsecond contents\n"
+
+	// setNotesFiles sets the notes files of the contents and returns their names.
+	setNotesFiles := func(t *testing.T) (string, string) {
+		t.Helper()
+
+		dir := t.TempDir()
+		file, other := filepath.Join(dir, "notes.md"), filepath.Join(dir, "other.md")
+		require.NoError(t, os.WriteFile(file, []byte(contents), 0o600))
+		require.NoError(t, os.WriteFile(other, []byte("# other\nother contents\n"), 0o600))
+		t.Setenv("FCQS_NOTES_FILE", "")
+		t.Setenv("FCQS_NOTES_FILES", test.MultiFiles(file, other))
+
+		return file, other
+	}
+
+	t.Run("rename", func(t *testing.T) {
+		file, _ := setNotesFiles(t)
+		setOSArgs(t, []string{"fcqs-cli", "rename", "first", "renamed"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Empty(t, buf.String())
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "# renamed\nfirst contents\n\n# second\nsecond contents\n", string(data))
+		backup, err := os.ReadFile(file + ".bak")
+		require.NoError(t, err)
+		assert.Equal(t, contents, string(backup))
+	})
+
+	t.Run("rm", func(t *testing.T) {
+		file, _ := setNotesFiles(t)
+		setOSArgs(t, []string{"fcqs-cli", "rm", "first"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "# second\nsecond contents\n", string(data))
+	})
+
+	t.Run("rm the last note", func(t *testing.T) {
+		file, _ := setNotesFiles(t)
+		setOSArgs(t, []string{"fcqs-cli", "rm", "second"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		// The file ends with exactly one newline.
+		assert.Equal(t, "# first\nfirst contents\n", string(data))
+	})
+
+	t.Run("mv", func(t *testing.T) {
+		file, other := setNotesFiles(t)
+		setOSArgs(t, []string{"fcqs-cli", "mv", "second", "--to", other})
+		setCommandLineString(t, "to", other)
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "# first\nfirst contents\n", string(data))
+		data, err = os.ReadFile(other)
+		require.NoError(t, err)
+		assert.Equal(t, "# other\nother contents\n\n# second\nsecond contents\n", string(data))
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		file, _ := setNotesFiles(t)
+		setOSArgs(t, []string{"fcqs-cli", "rm", "--dry-run", "second"})
+		setCommandLineFlag(t, "dry-run")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "--- "+file+"\n+++ "+file+"\n@@ -1,5 +1,2 @@\n # first\n first contents\n-\n-# second\n-second contents\n", buf.String())
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, contents, string(data))
+		assert.NoFileExists(t, file+".bak")
+	})
+
+	t.Run("mv without destination", func(t *testing.T) {
+		setNotesFiles(t)
+		setOSArgs(t, []string{"fcqs-cli", "mv", "second"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, ErrNoMoveDestination)
+	})
+
+	t.Run("note not found", func(t *testing.T) {
+		setNotesFiles(t)
+		setOSArgs(t, []string{"fcqs-cli", "rm", "no title"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, fcqs.ErrNoteNotFound)
+	})
+
+	t.Run("subcommand name after dash as title", func(t *testing.T) {
+		setNotesFiles(t)
+		setOSArgs(t, []string{"fcqs-cli", "--", "rm", "first"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, ErrInvalidNumberOfArgs)
+	})
+}
+
+func TestRunWithLintCommand(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.md")
+	require.NoError(t, os.WriteFile(file, []byte("#title\ncontents\n"), 0o600))
+	t.Setenv("FCQS_NOTES_FILE", "")
+
+	t.Run("problems", func(t *testing.T) {
+		t.Setenv("FCQS_NOTES_FILES", file)
+		setOSArgs(t, []string{"fcqs-cli", "lint"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, ErrLintProblems)
+		require.EqualError(t, err, "problems found in notes files: 1")
+		assert.Equal(t, file+":1: no space after \"#\" in heading (heading-space)\n", buf.String())
+	})
+
+	t.Run("problems in JSON", func(t *testing.T) {
+		t.Setenv("FCQS_NOTES_FILES", file)
+		setOSArgs(t, []string{"fcqs-cli", "--format", "json", "lint"})
+		setCommandLineString(t, "format", "json")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.ErrorIs(t, err, ErrLintProblems)
+		assert.JSONEq(t, `[{"file":"`+file+`","line":1,"rule":"heading-space","message":"no space after \"#\" in heading"}]`, buf.String())
+	})
+
+	t.Run("note titled lint after dash", func(t *testing.T) {
+		notes := filepath.Join(t.TempDir(), "notes.md")
+		require.NoError(t, os.WriteFile(notes, []byte("# lint\nnote contents\n"), 0o600))
+		t.Setenv("FCQS_NOTES_FILES", notes)
+		setOSArgs(t, []string{"fcqs-cli", "--", "lint"})
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "# lint\nnote contents\n", buf.String())
+	})
+
+	t.Run("no problems in JSON", func(t *testing.T) {
+		t.Setenv("FCQS_NOTES_FILES", test.GrepFile)
+		setOSArgs(t, []string{"fcqs-cli", "--format", "json", "lint"})
+		setCommandLineString(t, "format", "json")
+
+		var buf bytes.Buffer
+		err := run(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "[]\n", buf.String())
+	})
+}
+
+func TestRunWithCommandsInDocumentOrder(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "notes.md")
+	err := os.WriteFile(file, []byte("# mixed\n\n```sh\necho one\n```\n\n```bash\necho two\n```\n\n```sh\necho three\n```\n"), 0o600)
+	require.NoError(t, err)
+	t.Setenv("FCQS_NOTES_FILE", file)
+	t.Setenv("FCQS_NOTES_FILES", "")
+
+	tests := []struct {
+		name   string
+		args   []string
+		flag   string
+		index  string
+		expect string
+	}{
+		{
+			name:   "list commands",
+			args:   []string{"fcqs-cli", "--list-commands", "--shell", "bash", "mixed"},
+			flag:   "list-commands",
+			expect: "1\tmixed\n2\techo two\n3\techo three\n",
+		},
+		{
+			name:   "command index",
+			args:   []string{"fcqs-cli", "--command-index", "1", "--shell", "bash", "mixed"},
+			index:  "1",
+			expect: "echo one\n",
+		},
+		{
+			name:   "all commands",
+			args:   []string{"fcqs-cli", "--all-commands", "--shell", "bash", "mixed"},
+			flag:   "all-commands",
+			expect: "echo one\necho two\necho three\n",
+		},
+		{
+			name:   "first command prefers the shell",
+			args:   []string{"fcqs-cli", "-c", "--shell", "bash", "mixed"},
+			flag:   "command",
+			expect: "echo two\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			setOSArgs(t, tc.args)
+			setCommandLineString(t, "shell", "bash")
+			if tc.flag != "" {
+				setCommandLineFlag(t, tc.flag)
+			}
+			if tc.index != "" {
+				setCommandLineString(t, "command-index", tc.index)
+			}
+
+			var buf bytes.Buffer
+			err := run(&buf)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, buf.String())
+		})
+	}
 }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/yendo/fcqs"
+)
+
+// projectTitles returns the titles in the project notes files, which are title paths with --hierarchical.
+// The project notes files are rewound to scan them from the beginning.
+func projectTitles(notes *fcqs.NotesFiles) (map[string]bool, error) {
+	var projectFiles fcqs.NotesFiles
+	for i, file := range notes.Files {
+		if i < len(notes.Sources) && notes.Sources[i] == fcqs.SourceProject {
+			projectFiles.Files = append(projectFiles.Files, file)
+		}
+	}
+
+	titles := make(map[string]bool)
+	if len(projectFiles.Files) == 0 {
+		return titles, nil
+	}
+
+	if err := rewindNotesFiles(&projectFiles); err != nil {
+		return nil, err
+	}
+	locations, err := fcqs.TitleLocations(projectFiles.Files, *hierarchical)
+	if err != nil {
+		return nil, err
+	}
+	for _, tl := range locations {
+		titles[tl.Title] = true
+	}
+
+	return titles, nil
+}
+
+// sortProjectTitles moves the titles of the project notes ahead of the others keeping their order.
+func sortProjectTitles(titles []string, projects map[string]bool) {
+	slices.SortStableFunc(titles, func(a, b string) int {
+		switch {
+		case projects[a] == projects[b]:
+			return 0
+		case projects[a]:
+			return -1
+		default:
+			return 1
+		}
+	})
+}
+
+// titleSource returns the source of the notes with the title.
+func titleSource(title string, projects map[string]bool) string {
+	if projects[title] {
+		return fcqs.SourceProject
+	}
+
+	return fcqs.SourceGlobal
+}
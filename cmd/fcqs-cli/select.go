@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/yendo/fcqs"
+	"github.com/yendo/fcqs/internal/finder"
+	"github.com/yendo/fcqs/internal/value"
+)
+
+// ttyFile is the terminal for the finder, which is replaced for test.
+var ttyFile = "/dev/tty"
+
+// defaultEditCommandVSCode is the edit command when the editor is vscode.
+const defaultEditCommandVSCode = `awk '{printf "%s:%s\n",$1,$2}' | xargs -o code -g`
+
+// getenv returns the value of the environment variable or the default value if it is empty.
+func getenv(key, defaultValue string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return defaultValue
+}
+
+// writeSelectedTitle writes the title selected with the built-in fuzzy finder.
+func writeSelectedTitle(w io.Writer, notes *fcqs.NotesFiles) error {
+	data, err := io.ReadAll(notes.Reader)
+	if err != nil {
+		return fmt.Errorf("read notes: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if *hierarchical {
+		err = fcqs.WriteHierarchicalTitles(&buf, bytes.NewReader(data), false)
+	} else {
+		err = fcqs.WriteTitles(&buf, bytes.NewReader(data))
+	}
+	if err != nil {
+		return err
+	}
+	// The finder is not opened without titles to select.
+	if buf.Len() == 0 {
+		return nil
+	}
+	titles := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if err := sortTitles(titles, notes); err != nil {
+		return err
+	}
+
+	actions, err := finderActions(config)
+	if err != nil {
+		return err
+	}
+
+	preview := func(title string) string {
+		var buf bytes.Buffer
+		writePreview(&buf, bytes.NewReader(data), title) //nolint:errcheck
+		return buf.String()
+	}
+
+	title, err := runFinder(finder.New(titles, preview, actions))
+	if err != nil {
+		return err
+	}
+	if title != "" {
+		fmt.Fprintln(w, title)
+	}
+
+	return nil
+}
+
+// writeSelectedCmdLineBlockIndex writes the number of the command-line block in the note
+// selected with the built-in fuzzy finder.
+func writeSelectedCmdLineBlockIndex(w io.Writer, notes *fcqs.NotesFiles, arg string) error {
+	blocks, err := readCmdLineBlocks(notes, arg)
+	if err != nil {
+		return err
+	}
+
+	items := make([]string, 0, len(blocks))
+	commands := make(map[string]string, len(blocks))
+	for i, block := range blocks {
+		item := fmt.Sprintf("%d. %s", i+1, block.Label)
+		items = append(items, item)
+		commands[item] = block.Command
+	}
+
+	preview := func(item string) string {
+		return commands[item]
+	}
+
+	item, err := runFinder(finder.New(items, preview, nil))
+	if err != nil {
+		return err
+	}
+	if i := slices.Index(items, item); item != "" && i >= 0 {
+		fmt.Fprintln(w, i+1)
+	}
+
+	return nil
+}
+
+// writeSelectedURL writes the URL in the note selected with the built-in fuzzy finder.
+// The finder is not shown if the note has only one URL.
+func writeSelectedURL(w io.Writer, notes *fcqs.NotesFiles, arg string) error {
+	links, err := readLinks(notes, arg)
+	if err != nil {
+		return err
+	}
+
+	if len(links) <= 1 {
+		for _, link := range links {
+			fmt.Fprintln(w, link.URL)
+		}
+		return nil
+	}
+
+	items := make([]string, 0, len(links))
+	urls := make(map[string]string, len(links))
+	for i, link := range links {
+		item := fmt.Sprintf("%d. %s", i+1, link.Text)
+		items = append(items, item)
+		urls[item] = link.URL
+	}
+
+	preview := func(item string) string {
+		return urls[item]
+	}
+
+	item, err := runFinder(finder.New(items, preview, nil))
+	if err != nil {
+		return err
+	}
+	if url, ok := urls[item]; ok {
+		fmt.Fprintln(w, url)
+	}
+
+	return nil
+}
+
+// runFinder runs the finder on the terminal and returns the selected item.
+func runFinder(f *finder.Finder) (string, error) {
+	tty, err := os.OpenFile(ttyFile, os.O_RDWR, 0)
+	if err != nil {
+		return "", fmt.Errorf("open terminal: %w", err)
+	}
+	defer tty.Close()
+
+	return f.Run(tty)
+}
+
+// writePreview writes the contents of the note for the preview.
+func writePreview(w io.Writer, r io.Reader, title string) error {
+	if *hierarchical {
+		path, err := value.NewTitlePath(title)
+		if err != nil {
+			return err
+		}
+		return fcqs.WriteHierarchicalContents(w, r, path, false)
+	}
+
+	t, err := value.NewTitle(title)
+	if err != nil {
+		return err
+	}
+
+	return fcqs.WriteContents(w, r, t, false)
+}
+
+// finderActions returns the copy, open and edit actions of the finder with the settings.
+func finderActions(cfg *fcqs.Config) ([]finder.Action, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("executable: %w", err)
+	}
+	// The text format is explicit since the config file may make JSON the default.
+	cli := finder.ShellQuote(exe) + " --format text"
+	if *hierarchical {
+		cli += " --hierarchical"
+	}
+
+	copyFlag := ""
+	if getenv("FCQS_COPY_WITH_TITLE", "true") != "true" {
+		copyFlag = "-t"
+	}
+
+	editCommand := cfg.Commands.Edit
+	if editCommand == "" {
+		editCommand = `awk '{printf "+%s %s\n",$2,$1}' | xargs -o ` + os.Getenv("VISUAL") + " > /dev/tty"
+		if cfg.Commands.Editor == "vscode" {
+			editCommand = defaultEditCommandVSCode
+		}
+	}
+
+	var keys [3]finder.Key
+	for i, setting := range []struct{ env, key string }{
+		{"FCQS_COPY_KEY", cfg.Keys.Copy},
+		{"FCQS_OPEN_KEY", cfg.Keys.Open},
+		{"FCQS_EDIT_KEY", cfg.Keys.Edit},
+	} {
+		keys[i], err = finder.ParseKey(setting.key)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", setting.env, err)
+		}
+	}
+
+	return []finder.Action{
+		{Key: keys[0], Command: fmt.Sprintf("%s %s -- {} | %s", cli, copyFlag, cfg.Commands.Copy)},
+		{Key: keys[1], Command: fmt.Sprintf("%s --select --urls -- {} | xargs %s", cli, cfg.Commands.Open), Interactive: true},
+		{Key: keys[2], Command: fmt.Sprintf("%s -l -- {} | %s", cli, editCommand), Abort: true},
+	}, nil
+}
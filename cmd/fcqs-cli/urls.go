@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/yendo/fcqs"
+)
+
+// writeLinks writes the numbers, the texts and the URLs in the note separated by tabs.
+func writeLinks(w io.Writer, notes *fcqs.NotesFiles, arg string) error {
+	links, err := readLinks(notes, arg)
+	if err != nil {
+		return err
+	}
+
+	for i, link := range links {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", i+1, link.Text, link.URL)
+	}
+
+	return nil
+}
+
+// writeURL writes the URL in the note specified by the url-index option.
+func writeURL(w io.Writer, notes *fcqs.NotesFiles, arg string) error {
+	links, err := readLinks(notes, arg)
+	if err != nil {
+		return err
+	}
+
+	if *urlIndex <= len(links) {
+		fmt.Fprintln(w, links[*urlIndex-1].URL)
+	}
+
+	return nil
+}
+
+// readLinks returns the URLs in the note.
+func readLinks(notes *fcqs.NotesFiles, arg string) ([]fcqs.Link, error) {
+	var buf bytes.Buffer
+	if err := writeContents(&buf, notes, arg, false); err != nil {
+		return nil, err
+	}
+
+	return fcqs.Links(&buf)
+}
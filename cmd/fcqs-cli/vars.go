@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/yendo/fcqs"
+)
+
+// writeExpandedCmdLineBlock writes the first command line block whose placeholders are replaced.
+// The values are given by the vars option, and the others are asked on the terminal with the prompt option.
+func writeExpandedCmdLineBlock(w io.Writer, notes *fcqs.NotesFiles, arg string) error {
+	var buf bytes.Buffer
+	if err := writeCmdLineBlock(&buf, notes, arg); err != nil {
+		return err
+	}
+	cmd := buf.String()
+
+	values := maps.Clone(*vars)
+	if values == nil {
+		values = make(map[string]string)
+	}
+
+	if *promptVars {
+		var placeholders []fcqs.Placeholder
+		for _, p := range fcqs.Placeholders(cmd) {
+			if _, ok := values[p.Name]; !ok {
+				placeholders = append(placeholders, p)
+			}
+		}
+
+		if len(placeholders) > 0 {
+			tty, err := os.OpenFile(ttyFile, os.O_RDWR, 0)
+			if err != nil {
+				return fmt.Errorf("open terminal: %w", err)
+			}
+			defer tty.Close()
+
+			if err := askPlaceholders(tty, tty, placeholders, values); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprint(w, fcqs.ExpandPlaceholders(cmd, values))
+
+	return nil
+}
+
+// askPlaceholders asks the values of the placeholders and stores them to the values.
+// An empty answer leaves the placeholder to its default value.
+func askPlaceholders(in io.Reader, out io.Writer, placeholders []fcqs.Placeholder, values map[string]string) error {
+	reader := bufio.NewReader(in)
+
+	for _, p := range placeholders {
+		if p.HasDefault {
+			fmt.Fprintf(out, "%s [%s]: ", p.Name, p.Default)
+		} else {
+			fmt.Fprintf(out, "%s: ", p.Name)
+		}
+
+		answer, err := reader.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || answer == "") {
+			return fmt.Errorf("read value of %s: %w", p.Name, err)
+		}
+
+		if answer = strings.TrimRight(answer, "\r\n"); answer != "" {
+			values[p.Name] = answer
+		}
+	}
+
+	return nil
+}
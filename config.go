@@ -0,0 +1,239 @@
+package fcqs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	configDir  = "fcqs"
+	configFile = "config.toml"
+)
+
+var ErrUnknownConfigKey = errors.New("unknown config key")
+
+// Config represents the settings of fcqs.
+type Config struct {
+	Notes    NotesConfig    `toml:"notes" json:"notes"`
+	Fences   FencesConfig   `toml:"fences" json:"fences"`
+	Keys     KeysConfig     `toml:"keys" json:"keys"`
+	Commands CommandsConfig `toml:"commands" json:"commands"`
+	Output   OutputConfig   `toml:"output" json:"output"`
+
+	file string
+	meta toml.MetaData
+}
+
+// NotesConfig represents the sources of notes.
+type NotesConfig struct {
+	// Files are notes files, directories and glob patterns.
+	Files []string `toml:"files" json:"files"`
+	// Ignore are patterns of names that are not searched in directories.
+	Ignore []string `toml:"ignore" json:"ignore"`
+	// Project enables the notes files of the project in the working directory ahead of Files.
+	Project bool `toml:"project" json:"project"`
+}
+
+// FencesConfig represents the languages of fenced code blocks.
+type FencesConfig struct {
+	// Shells maps fence languages to shells in addition to the built-in languages.
+	Shells map[string]string `toml:"shells" json:"shells"`
+}
+
+// KeysConfig represents the key bindings of the shell integration.
+type KeysConfig struct {
+	Copy string `toml:"copy" json:"copy"`
+	Open string `toml:"open" json:"open"`
+	Edit string `toml:"edit" json:"edit"`
+	Bash string `toml:"bash" json:"bash"`
+	Zsh  string `toml:"zsh" json:"zsh"`
+	Fish string `toml:"fish" json:"fish"`
+	Pwsh string `toml:"pwsh" json:"pwsh"`
+	// The capture keys bind capturing the last command as a note.
+	BashCapture string `toml:"bash_capture" json:"bash_capture"`
+	ZshCapture  string `toml:"zsh_capture" json:"zsh_capture"`
+	FishCapture string `toml:"fish_capture" json:"fish_capture"`
+	PwshCapture string `toml:"pwsh_capture" json:"pwsh_capture"`
+}
+
+// CommandsConfig represents the commands run for notes.
+type CommandsConfig struct {
+	Copy string `toml:"copy" json:"copy"`
+	Open string `toml:"open" json:"open"`
+	// Edit is the command opening the note location, which is derived from Editor if it is empty.
+	Edit   string `toml:"edit" json:"edit"`
+	Editor string `toml:"editor" json:"editor"`
+}
+
+// OutputConfig represents the defaults of output options.
+type OutputConfig struct {
+	Format       string `toml:"format" json:"format"`
+	Sort         string `toml:"sort" json:"sort"`
+	Hierarchical bool   `toml:"hierarchical" json:"hierarchical"`
+}
+
+// setting represents a config key and the environment variable that takes precedence over it.
+type setting struct {
+	key   []string
+	env   string
+	value *string
+}
+
+// settings returns the string settings that can be overridden by environment variables.
+func (c *Config) settings() []setting {
+	return []setting{
+		{[]string{"keys", "copy"}, "FCQS_COPY_KEY", &c.Keys.Copy},
+		{[]string{"keys", "open"}, "FCQS_OPEN_KEY", &c.Keys.Open},
+		{[]string{"keys", "edit"}, "FCQS_EDIT_KEY", &c.Keys.Edit},
+		{[]string{"keys", "bash"}, "FCQS_BASH_BIND_KEY", &c.Keys.Bash},
+		{[]string{"keys", "zsh"}, "FCQS_ZSH_BIND_KEY", &c.Keys.Zsh},
+		{[]string{"keys", "fish"}, "FCQS_FISH_BIND_KEY", &c.Keys.Fish},
+		{[]string{"keys", "pwsh"}, "FCQS_PWSH_BIND_KEY", &c.Keys.Pwsh},
+		{[]string{"keys", "bash_capture"}, "FCQS_BASH_CAPTURE_KEY", &c.Keys.BashCapture},
+		{[]string{"keys", "zsh_capture"}, "FCQS_ZSH_CAPTURE_KEY", &c.Keys.ZshCapture},
+		{[]string{"keys", "fish_capture"}, "FCQS_FISH_CAPTURE_KEY", &c.Keys.FishCapture},
+		{[]string{"keys", "pwsh_capture"}, "FCQS_PWSH_CAPTURE_KEY", &c.Keys.PwshCapture},
+		{[]string{"commands", "copy"}, "FCQS_COPY_COMMAND", &c.Commands.Copy},
+		{[]string{"commands", "open"}, "FCQS_OPEN_COMMAND", &c.Commands.Open},
+		{[]string{"commands", "edit"}, "FCQS_EDIT_COMMAND", &c.Commands.Edit},
+		{[]string{"commands", "editor"}, "FCQS_EDITOR", &c.Commands.Editor},
+		{[]string{"output", "sort"}, "FCQS_SORT", &c.Output.Sort},
+	}
+}
+
+// DefaultConfig returns the default settings, which are the same as the shell integration scripts.
+func DefaultConfig() *Config {
+	return &Config{
+		Notes: NotesConfig{
+			Project: true,
+		},
+		Keys: KeysConfig{
+			Copy: "ctrl-y",
+			Open: "ctrl-o",
+			Edit: "ctrl-e",
+			Bash: `\C-o`,
+			Zsh:  "^o",
+			Fish: `\co`,
+			Pwsh: "Ctrl+o",
+
+			BashCapture: `\C-xc`,
+			ZshCapture:  "^xc",
+			FishCapture: `\cxc`,
+			PwshCapture: "Ctrl+x,c",
+		},
+		Commands: CommandsConfig{
+			Copy:   "xclip -selection c",
+			Open:   "open",
+			Editor: "default",
+		},
+		Output: OutputConfig{
+			Format: "text",
+			Sort:   "file",
+		},
+	}
+}
+
+// ConfigFile returns the path of the config file.
+// FCQS_CONFIG takes precedence over the user config directory, and
+// it returns an empty string if the user config directory is not available.
+func ConfigFile() string {
+	if f := os.Getenv("FCQS_CONFIG"); f != "" {
+		return f
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, configDir, configFile)
+}
+
+// LoadConfig returns the settings in the config file over the default settings.
+// Environment variables take precedence over the config file,
+// and the default settings are used if the file is empty or does not exist.
+func LoadConfig(file string) (*Config, error) {
+	cfg := DefaultConfig()
+
+	if file != "" {
+		meta, err := toml.DecodeFile(file, cfg)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("config file: %w", err)
+		default:
+			if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+				return nil, fmt.Errorf("config file: %w: %s", ErrUnknownConfigKey, undecoded[0])
+			}
+			cfg.file, cfg.meta = file, meta
+		}
+	}
+
+	for _, s := range cfg.settings() {
+		if v := os.Getenv(s.env); v != "" {
+			*s.value = v
+		}
+	}
+	if v := os.Getenv("FCQS_HIERARCHICAL"); v != "" {
+		cfg.Output.Hierarchical = v == "true"
+	}
+	if v := os.Getenv("FCQS_PROJECT_NOTES"); v != "" {
+		cfg.Notes.Project = v == "true"
+	}
+
+	return cfg, nil
+}
+
+// File returns the path of the config file, which is empty if no file is used.
+func (c *Config) File() string {
+	return c.file
+}
+
+// Resolve returns the notes files and the ignore patterns actually used,
+// which are taken from environment variables, the config or the defaults.
+// The notes files of the project are not included, see ProjectFiles.
+func (c NotesConfig) Resolve() (NotesConfig, error) {
+	files, err := notesFileNames(c)
+	if err != nil {
+		return NotesConfig{}, fmt.Errorf("notes file name: %w", err)
+	}
+
+	return NotesConfig{Files: files, Ignore: ignorePatterns(c), Project: c.Project}, nil
+}
+
+// ProjectFiles returns the notes files of the project in the working directory,
+// which are used ahead of the notes files if Project is true.
+func (c NotesConfig) ProjectFiles() ([]string, error) {
+	files, err := projectNotesFileNames(c)
+	if err != nil {
+		return nil, fmt.Errorf("project notes file name: %w", err)
+	}
+
+	return files, nil
+}
+
+// WriteConfig writes the settings in the TOML format.
+func WriteConfig(w io.Writer, cfg *Config) error {
+	if err := toml.NewEncoder(w).Encode(cfg); err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+
+	return nil
+}
+
+// isDefined reports whether the key is written in the config file.
+func (c *Config) isDefined(key ...string) bool {
+	return c.meta.IsDefined(key...)
+}
+
+// splitList splits the list of an environment variable separated by PathListSeparator.
+func splitList(s string) []string {
+	return strings.Split(s, string(os.PathListSeparator))
+}
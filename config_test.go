@@ -0,0 +1,197 @@
+package fcqs_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yendo/fcqs"
+)
+
+// writeConfigFile writes the config file in a temporary directory and returns its path.
+func writeConfigFile(t *testing.T, contents string) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(file, []byte(contents), 0o600))
+
+	return file
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("FCQS_COPY_KEY", "")
+	t.Setenv("FCQS_SORT", "")
+	t.Setenv("FCQS_HIERARCHICAL", "")
+	t.Setenv("FCQS_PROJECT_NOTES", "")
+
+	t.Run("default settings", func(t *testing.T) {
+		cfg, err := fcqs.LoadConfig(filepath.Join(t.TempDir(), "config.toml"))
+
+		require.NoError(t, err)
+		assert.Equal(t, fcqs.DefaultConfig(), cfg)
+		assert.Empty(t, cfg.File())
+	})
+
+	t.Run("config file", func(t *testing.T) {
+		file := writeConfigFile(t, `
+[notes]
+files = ["notes.md", "notes"]
+
+[fences]
+shells = { ksh = "sh" }
+
+[keys]
+copy = "ctrl-k"
+
+[output]
+hierarchical = true
+`)
+
+		cfg, err := fcqs.LoadConfig(file)
+
+		require.NoError(t, err)
+		assert.Equal(t, file, cfg.File())
+		assert.Equal(t, []string{"notes.md", "notes"}, cfg.Notes.Files)
+		assert.Equal(t, map[string]string{"ksh": "sh"}, cfg.Fences.Shells)
+		assert.Equal(t, "ctrl-k", cfg.Keys.Copy)
+		assert.Equal(t, "ctrl-o", cfg.Keys.Open)
+		assert.True(t, cfg.Output.Hierarchical)
+		assert.Equal(t, "file", cfg.Output.Sort)
+	})
+
+	t.Run("environment variables take precedence", func(t *testing.T) {
+		t.Setenv("FCQS_COPY_KEY", "ctrl-x")
+		t.Setenv("FCQS_SORT", "frecency")
+		t.Setenv("FCQS_HIERARCHICAL", "false")
+		t.Setenv("FCQS_PROJECT_NOTES", "false")
+		file := writeConfigFile(t, "[keys]\ncopy = \"ctrl-k\"\n[output]\nhierarchical = true\n")
+
+		cfg, err := fcqs.LoadConfig(file)
+
+		require.NoError(t, err)
+		assert.Equal(t, "ctrl-x", cfg.Keys.Copy)
+		assert.Equal(t, "frecency", cfg.Output.Sort)
+		assert.False(t, cfg.Output.Hierarchical)
+		assert.False(t, cfg.Notes.Project)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		file := writeConfigFile(t, "[keys]\ncopy = \"ctrl-k\"\npaste = \"ctrl-v\"\n")
+
+		cfg, err := fcqs.LoadConfig(file)
+
+		require.ErrorIs(t, err, fcqs.ErrUnknownConfigKey)
+		require.EqualError(t, err, "config file: unknown config key: keys.paste")
+		assert.Nil(t, cfg)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		file := writeConfigFile(t, "[keys\n")
+
+		cfg, err := fcqs.LoadConfig(file)
+
+		require.ErrorContains(t, err, "config file: ")
+		assert.Nil(t, cfg)
+	})
+}
+
+func TestConfigFile(t *testing.T) {
+	t.Run("user config directory", func(t *testing.T) {
+		t.Setenv("FCQS_CONFIG", "")
+		t.Setenv("XDG_CONFIG_HOME", "/tmp/config")
+
+		assert.Equal(t, "/tmp/config/fcqs/config.toml", fcqs.ConfigFile())
+	})
+
+	t.Run("environment variable", func(t *testing.T) {
+		t.Setenv("FCQS_CONFIG", "/tmp/fcqs.toml")
+
+		assert.Equal(t, "/tmp/fcqs.toml", fcqs.ConfigFile())
+	})
+}
+
+func TestWriteShellConfig(t *testing.T) {
+	t.Setenv("FCQS_CONFIG", "")
+	t.Setenv("FCQS_COPY_KEY", "")
+	t.Setenv("FCQS_EDIT_COMMAND", "")
+
+	file := writeConfigFile(t, `
+[keys]
+copy = "ctrl-k"
+bash = "\\C-g"
+fish = "\\cg"
+bash_capture = "\\C-xn"
+
+[commands]
+edit = "code -g 'x'"
+`)
+	cfg, err := fcqs.LoadConfig(file)
+	require.NoError(t, err)
+
+	tests := []struct {
+		shell  string
+		expect string
+	}{
+		{
+			shell: "bash",
+			expect: "export FCQS_CONFIG='" + file + "'\n" +
+				`[ -n "${FCQS_COPY_KEY}" ] || FCQS_COPY_KEY='ctrl-k'` + "\n" +
+				`[ -n "${FCQS_BASH_BIND_KEY}" ] || FCQS_BASH_BIND_KEY='\C-g'` + "\n" +
+				`[ -n "${FCQS_BASH_CAPTURE_KEY}" ] || FCQS_BASH_CAPTURE_KEY='\C-xn'` + "\n" +
+				`[ -n "${FCQS_EDIT_COMMAND}" ] || FCQS_EDIT_COMMAND='code -g '\''x'\'''` + "\n",
+		},
+		{
+			shell: "fish",
+			expect: "set -gx FCQS_CONFIG '" + file + "'\n" +
+				`set -q FCQS_COPY_KEY; or set -g FCQS_COPY_KEY 'ctrl-k'` + "\n" +
+				`set -q FCQS_FISH_BIND_KEY; or set -g FCQS_FISH_BIND_KEY '\\cg'` + "\n" +
+				`set -q FCQS_EDIT_COMMAND; or set -g FCQS_EDIT_COMMAND 'code -g \'x\''` + "\n",
+		},
+		{
+			shell: "pwsh",
+			expect: "$env:FCQS_CONFIG = '" + file + "'\n" +
+				`if (-not $env:FCQS_COPY_KEY) { $env:FCQS_COPY_KEY = 'ctrl-k' }` + "\n" +
+				`if (-not $env:FCQS_EDIT_COMMAND) { $env:FCQS_EDIT_COMMAND = 'code -g ''x''' }` + "\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.shell, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := fcqs.WriteShellConfig(&buf, tc.shell, cfg)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, buf.String())
+		})
+	}
+
+	t.Run("default config file", func(t *testing.T) {
+		t.Setenv("FCQS_CONFIG", file)
+
+		var buf bytes.Buffer
+		err := fcqs.WriteShellConfig(&buf, "zsh", cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, `[[ -n "${FCQS_COPY_KEY}" ]] || FCQS_COPY_KEY='ctrl-k'`+"\n"+
+			`[[ -n "${FCQS_EDIT_COMMAND}" ]] || FCQS_EDIT_COMMAND='code -g '\''x'\'''`+"\n", buf.String())
+	})
+
+	t.Run("no config file", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := fcqs.WriteShellConfig(&buf, "bash", fcqs.DefaultConfig())
+
+		require.NoError(t, err)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("unsupported shell", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := fcqs.WriteShellConfig(&buf, "csh", cfg)
+
+		require.ErrorIs(t, err, fcqs.ErrUnsupportedShell)
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,350 @@
+package fcqs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/yendo/fcqs/internal/value"
+)
+
+// backupFileExt is the extension of the backup of a notes file rewritten by edits of notes.
+const backupFileExt = ".bak"
+
+var (
+	ErrDuplicateTitle = errors.New("duplicate title")
+	ErrEmptyNoteBody  = errors.New("empty note body")
+	ErrNoteNotFound   = errors.New("note not found")
+)
+
+// AddNote appends the note of the title and the body to the notes file, which is created if it does not exist.
+// It refuses the title that is already in the file, and
+// the file is replaced atomically so that readers never see a half-written file.
+func AddNote(file string, title *value.Title, body string) error {
+	body = strings.TrimRight(strings.TrimLeft(body, "\r\n"), " \t\r\n")
+	if body == "" {
+		return ErrEmptyNoteBody
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read notes file: %w", err)
+	}
+
+	nb, err := parseNotebook(bufio.NewScanner(bytes.NewReader(data)), file)
+	if err != nil {
+		return fmt.Errorf("parse notes: %w", err)
+	}
+	for note := range nb.find(title) {
+		return fmt.Errorf("%w: %s in %s:%d", ErrDuplicateTitle, title, note.File, note.Line)
+	}
+
+	data = appendNoteText(data, fmt.Sprintf("# %s\n\n%s\n", title, body))
+
+	return writeFileAtomically(file, data)
+}
+
+// appendNoteText appends the text of a note to the notes file data separated by a blank line.
+func appendNoteText(data []byte, text string) []byte {
+	if len(data) > 0 {
+		if !bytes.HasSuffix(data, []byte("\n")) {
+			data = append(data, '\n')
+		}
+		if !bytes.HasSuffix(data, []byte("\n\n")) {
+			data = append(data, '\n')
+		}
+	}
+
+	return append(data, text...)
+}
+
+// writeFileAtomically replaces the file with the data through a temporary file in the same directory.
+// The permission of the file is kept, and a symbolic link is followed to replace its target.
+func writeFileAtomically(file string, data []byte) error {
+	perm := fs.FileMode(0o600)
+	if info, err := os.Stat(file); err == nil {
+		perm = info.Mode().Perm()
+		if file, err = filepath.EvalSymlinks(file); err != nil {
+			return fmt.Errorf("notes file: %w", err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return fmt.Errorf("write notes file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write notes file: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write notes file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write notes file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("write notes file: %w", err)
+	}
+
+	return nil
+}
+
+// FencedCodeBlock returns the fenced code block of the code with the language.
+// The fence is longer than any backticks in the code so that the code never closes the block.
+func FencedCodeBlock(code, language string) string {
+	// A fence has three backticks at least.
+	length := 3
+	for _, line := range strings.Split(code, "\n") {
+		line = strings.TrimLeft(line, " ")
+		if n := len(line) - len(strings.TrimLeft(line, "`")); n >= length {
+			length = n + 1
+		}
+	}
+	fence := strings.Repeat("`", length)
+
+	return fence + language + "\n" + strings.TrimRight(code, "\n") + "\n" + fence + "\n"
+}
+
+// FileChange represents a change of a notes file by an edit of notes.
+type FileChange struct {
+	File string
+	// Old is nil if the file does not exist.
+	Old []byte
+	New []byte
+}
+
+// notesFileLines represents the lines of a notes file and the notes with a title in it.
+type notesFileLines struct {
+	file  string
+	data  []byte
+	lines []string
+	notes []Note
+}
+
+// readNotesFileLines returns the lines of the notes file and the notes with the title.
+// The lines keep their line endings, and a missing file has no lines.
+func readNotesFileLines(file string, title *value.Title) (*notesFileLines, error) {
+	data, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("read notes file: %w", err)
+	}
+
+	nb, err := parseNotebook(bufio.NewScanner(bytes.NewReader(data)), file)
+	if err != nil {
+		return nil, fmt.Errorf("parse notes: %w", err)
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return &notesFileLines{file: file, data: data, lines: lines, notes: slices.Collect(nb.find(title))}, nil
+}
+
+// change returns the change of the file to the lines.
+func (f *notesFileLines) change(lines []string) FileChange {
+	return FileChange{File: f.file, Old: f.data, New: []byte(strings.Join(lines, ""))}
+}
+
+// noteLines returns the lines of the note, which are from the title line to the line before the next title line.
+func (f *notesFileLines) noteLines(note Note) []string {
+	return f.lines[note.Line-1 : note.EndLine]
+}
+
+// without returns the lines without the notes.
+// The blank lines separating the last note from the others are removed with it.
+func (f *notesFileLines) without(notes []Note) []string {
+	var lines []string
+	next := 1
+	for _, note := range notes {
+		lines = append(lines, f.lines[next-1:note.Line-1]...)
+		next = note.EndLine + 1
+	}
+
+	if next > len(f.lines) {
+		for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+	}
+
+	return append(lines, f.lines[next-1:]...)
+}
+
+// lineEnding returns the line ending of the line, which is empty for the last line without a newline.
+func lineEnding(line string) string {
+	for _, eol := range []string{"\r\n", "\n"} {
+		if strings.HasSuffix(line, eol) {
+			return eol
+		}
+	}
+
+	return ""
+}
+
+// findNotesFileLines returns the lines of the notes files with the notes with the title.
+// It returns ErrNoteNotFound if no files have the notes.
+func findNotesFileLines(files []string, title *value.Title) ([]*notesFileLines, error) {
+	var found []*notesFileLines
+	for _, file := range files {
+		f, err := readNotesFileLines(file, title)
+		if err != nil {
+			return nil, err
+		}
+		if len(f.notes) > 0 {
+			found = append(found, f)
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoteNotFound, title)
+	}
+
+	return found, nil
+}
+
+// RenameNote returns the changes of the notes files renaming the notes with the title to the new title.
+// The title lines are rewritten keeping their heading levels, and the new title must not be used in the files.
+func RenameNote(files []string, title, newTitle *value.Title) ([]FileChange, error) {
+	if !title.Equals(newTitle) {
+		for _, file := range files {
+			f, err := readNotesFileLines(file, newTitle)
+			if err != nil {
+				return nil, err
+			}
+			if len(f.notes) > 0 {
+				return nil, fmt.Errorf("%w: %s in %s:%d", ErrDuplicateTitle, newTitle, file, f.notes[0].Line)
+			}
+		}
+	}
+
+	found, err := findNotesFileLines(files, title)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]FileChange, 0, len(found))
+	for _, f := range found {
+		lines := slices.Clone(f.lines)
+		for _, note := range f.notes {
+			line := lines[note.Line-1]
+			// The underline of a setext heading is kept.
+			heading := newTitle.String()
+			if !strings.Contains(note.heading, "\n") {
+				heading = strings.Repeat("#", note.Level) + " " + heading
+			}
+			lines[note.Line-1] = heading + lineEnding(line)
+		}
+		changes = append(changes, f.change(lines))
+	}
+
+	return changes, nil
+}
+
+// RemoveNote returns the changes of the notes files removing the notes with the title.
+func RemoveNote(files []string, title *value.Title) ([]FileChange, error) {
+	found, err := findNotesFileLines(files, title)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]FileChange, 0, len(found))
+	for _, f := range found {
+		changes = append(changes, f.change(f.without(f.notes)))
+	}
+
+	return changes, nil
+}
+
+// MoveNote returns the changes of the notes files moving the notes with the title to the end of the file.
+// The file must not have the title, and the change of the file comes first.
+func MoveNote(files []string, title *value.Title, to string) ([]FileChange, error) {
+	target, err := readNotesFileLines(to, title)
+	if err != nil {
+		return nil, err
+	}
+	if len(target.notes) > 0 {
+		return nil, fmt.Errorf("%w: %s in %s:%d", ErrDuplicateTitle, title, to, target.notes[0].Line)
+	}
+
+	found, err := findNotesFileLines(files, title)
+	if err != nil {
+		return nil, err
+	}
+
+	data := target.data
+	changes := make([]FileChange, 1, len(found)+1)
+	for _, f := range found {
+		for _, note := range f.notes {
+			text := strings.TrimRight(strings.Join(f.noteLines(note), ""), "\r\n")
+			data = appendNoteText(data, text+"\n")
+		}
+		changes = append(changes, f.change(f.without(f.notes)))
+	}
+	changes[0] = FileChange{File: to, Old: target.data, New: data}
+
+	return changes, nil
+}
+
+// ApplyFileChanges writes the changes to the files in order.
+// Each file is replaced atomically, and the old contents are kept in the backup file with .bak extension.
+func ApplyFileChanges(changes []FileChange) error {
+	for _, c := range changes {
+		if c.Old != nil {
+			if err := writeFileAtomically(c.File+backupFileExt, c.Old); err != nil {
+				return fmt.Errorf("backup: %w", err)
+			}
+		}
+		if err := writeFileAtomically(c.File, c.New); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteFileChangesDiff writes the changes in the unified diff format.
+func WriteFileChangesDiff(w io.Writer, changes []FileChange) error {
+	for _, c := range changes {
+		from := c.File
+		if c.Old == nil {
+			from = os.DevNull
+		}
+
+		err := difflib.WriteUnifiedDiff(w, difflib.UnifiedDiff{
+			A:        diffLines(c.Old),
+			B:        diffLines(c.New),
+			FromFile: from,
+			ToFile:   c.File,
+			Context:  3,
+		})
+		if err != nil {
+			return fmt.Errorf("write diff: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// diffLines returns the lines of the data for diff keeping their line endings.
+func diffLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
@@ -0,0 +1,336 @@
+package fcqs_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yendo/fcqs"
+	"github.com/yendo/fcqs/internal/value"
+)
+
+func TestAddNote(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		contents string
+		body     string
+		expected string
+	}{
+		{
+			name:     "new file",
+			body:     "contents\n",
+			expected: "# title\n\ncontents\n",
+		},
+		{
+			name:     "without newline at the end",
+			contents: "# first\nfirst contents",
+			body:     "contents",
+			expected: "# first\nfirst contents\n\n# title\n\ncontents\n",
+		},
+		{
+			name:     "with newline at the end",
+			contents: "# first\nfirst contents\n",
+			body:     "\n\ncontents\n\n",
+			expected: "# first\nfirst contents\n\n# title\n\ncontents\n",
+		},
+		{
+			name:     "with blank line at the end",
+			contents: "# first\nfirst contents\n\n",
+			body:     "  indented\n",
+			expected: "# first\nfirst contents\n\n# title\n\n  indented\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			file := filepath.Join(t.TempDir(), "notes.md")
+			if tc.contents != "" {
+				require.NoError(t, os.WriteFile(file, []byte(tc.contents), 0o644))
+			}
+			title, err := value.NewTitle(" title ")
+			require.NoError(t, err)
+
+			err = fcqs.AddNote(file, title, tc.body)
+
+			require.NoError(t, err)
+			data, err := os.ReadFile(file)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(data))
+		})
+	}
+}
+
+func TestAddNoteFail(t *testing.T) {
+	t.Parallel()
+
+	contents := "# title\ncontents\n"
+
+	t.Run("duplicate title", func(t *testing.T) {
+		t.Parallel()
+
+		file := filepath.Join(t.TempDir(), "notes.md")
+		require.NoError(t, os.WriteFile(file, []byte(contents), 0o600))
+		title, err := value.NewTitle("title")
+		require.NoError(t, err)
+
+		err = fcqs.AddNote(file, title, "other contents")
+
+		require.ErrorIs(t, err, fcqs.ErrDuplicateTitle)
+		require.EqualError(t, err, "duplicate title: title in "+file+":1")
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, contents, string(data))
+	})
+
+	t.Run("empty body", func(t *testing.T) {
+		t.Parallel()
+
+		file := filepath.Join(t.TempDir(), "notes.md")
+		title, err := value.NewTitle("title")
+		require.NoError(t, err)
+
+		err = fcqs.AddNote(file, title, "\n \n")
+
+		require.ErrorIs(t, err, fcqs.ErrEmptyNoteBody)
+		assert.NoFileExists(t, file)
+	})
+}
+
+func TestAddNoteToLink(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.md")
+	link := filepath.Join(dir, "link.md")
+	require.NoError(t, os.WriteFile(file, []byte("# first\ncontents\n"), 0o640))
+	require.NoError(t, os.Symlink(file, link))
+	title, err := value.NewTitle("title")
+	require.NoError(t, err)
+
+	err = fcqs.AddNote(link, title, "contents")
+
+	require.NoError(t, err)
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode().Type())
+
+	info, err = os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "# first\ncontents\n\n# title\n\ncontents\n", string(data))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestFencedCodeBlock(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		code     string
+		language string
+		expected string
+	}{
+		{name: "command", code: "ls -l\n", language: "bash", expected: "```bash\nls -l\n```\n"},
+		{name: "backticks in command", code: "echo `date`", language: "sh", expected: "```sh\necho `date`\n```\n"},
+		{name: "fence in code", code: "cat <<EOF\n  ````\nEOF", language: "zsh", expected: "`````zsh\ncat <<EOF\n  ````\nEOF\n`````\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, fcqs.FencedCodeBlock(tc.code, tc.language))
+		})
+	}
+}
+
+const editNotesContents = "# first\n\n```sh\n# target\n```\n\n## target\n\ntarget contents\n\n# last\nlast contents\n"
+
+// writeEditNotesFiles writes the notes files for edits and returns their names.
+func writeEditNotesFiles(t *testing.T, contents ...string) []string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := make([]string, 0, len(contents))
+	for i, c := range contents {
+		file := filepath.Join(dir, fmt.Sprintf("notes%d.md", i))
+		require.NoError(t, os.WriteFile(file, []byte(c), 0o600))
+		files = append(files, file)
+	}
+
+	return files
+}
+
+func newTitle(t *testing.T, s string) *value.Title {
+	t.Helper()
+
+	title, err := value.NewTitle(s)
+	require.NoError(t, err)
+
+	return title
+}
+
+func TestRenameNote(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rename title lines", func(t *testing.T) {
+		t.Parallel()
+
+		files := writeEditNotesFiles(t, editNotesContents, "other\n", "target\r\n------\r\ncontents\r\n")
+
+		changes, err := fcqs.RenameNote(files, newTitle(t, "target"), newTitle(t, "renamed"))
+
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		assert.Equal(t, files[0], changes[0].File)
+		assert.Equal(t, editNotesContents, string(changes[0].Old))
+		assert.Equal(t, "# first\n\n```sh\n# target\n```\n\n## renamed\n\ntarget contents\n\n# last\nlast contents\n", string(changes[0].New))
+		assert.Equal(t, files[2], changes[1].File)
+		assert.Equal(t, "renamed\r\n------\r\ncontents\r\n", string(changes[1].New))
+	})
+
+	t.Run("duplicate title", func(t *testing.T) {
+		t.Parallel()
+
+		files := writeEditNotesFiles(t, editNotesContents)
+
+		changes, err := fcqs.RenameNote(files, newTitle(t, "target"), newTitle(t, "last"))
+
+		require.ErrorIs(t, err, fcqs.ErrDuplicateTitle)
+		assert.Nil(t, changes)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		files := writeEditNotesFiles(t, editNotesContents)
+
+		changes, err := fcqs.RenameNote(files, newTitle(t, "no title"), newTitle(t, "renamed"))
+
+		require.ErrorIs(t, err, fcqs.ErrNoteNotFound)
+		require.EqualError(t, err, "note not found: no title")
+		assert.Nil(t, changes)
+	})
+}
+
+func TestRemoveNote(t *testing.T) {
+	t.Parallel()
+
+	files := writeEditNotesFiles(t, editNotesContents, "# target\ncontents\n# target\nmore\n")
+
+	changes, err := fcqs.RemoveNote(files, newTitle(t, "target"))
+
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "# first\n\n```sh\n# target\n```\n\n# last\nlast contents\n", string(changes[0].New))
+	assert.Empty(t, string(changes[1].New))
+
+	t.Run("last note", func(t *testing.T) {
+		t.Parallel()
+
+		files := writeEditNotesFiles(t, editNotesContents+"\n\n")
+
+		changes, err := fcqs.RemoveNote(files, newTitle(t, "last"))
+
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Equal(t, "# first\n\n```sh\n# target\n```\n\n## target\n\ntarget contents\n", string(changes[0].New))
+	})
+}
+
+func TestMoveNote(t *testing.T) {
+	t.Parallel()
+
+	t.Run("move to the end of the file", func(t *testing.T) {
+		t.Parallel()
+
+		files := writeEditNotesFiles(t, editNotesContents, "# other\ncontents")
+
+		changes, err := fcqs.MoveNote(files, newTitle(t, "target"), files[1])
+
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		assert.Equal(t, files[1], changes[0].File)
+		assert.Equal(t, "# other\ncontents\n\n## target\n\ntarget contents\n", string(changes[0].New))
+		assert.Equal(t, files[0], changes[1].File)
+		assert.Equal(t, "# first\n\n```sh\n# target\n```\n\n# last\nlast contents\n", string(changes[1].New))
+	})
+
+	t.Run("move to a new file", func(t *testing.T) {
+		t.Parallel()
+
+		files := writeEditNotesFiles(t, editNotesContents)
+		to := filepath.Join(t.TempDir(), "new.md")
+
+		changes, err := fcqs.MoveNote(files, newTitle(t, "last"), to)
+
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		assert.Nil(t, changes[0].Old)
+		assert.Equal(t, "# last\nlast contents\n", string(changes[0].New))
+	})
+
+	t.Run("title in the file", func(t *testing.T) {
+		t.Parallel()
+
+		files := writeEditNotesFiles(t, editNotesContents)
+
+		changes, err := fcqs.MoveNote(files, newTitle(t, "last"), files[0])
+
+		require.ErrorIs(t, err, fcqs.ErrDuplicateTitle)
+		assert.Nil(t, changes)
+	})
+}
+
+func TestApplyFileChanges(t *testing.T) {
+	t.Parallel()
+
+	files := writeEditNotesFiles(t, editNotesContents)
+	to := filepath.Join(t.TempDir(), "new.md")
+	changes, err := fcqs.MoveNote(files, newTitle(t, "last"), to)
+	require.NoError(t, err)
+
+	err = fcqs.ApplyFileChanges(changes)
+
+	require.NoError(t, err)
+	for _, c := range changes {
+		data, err := os.ReadFile(c.File)
+		require.NoError(t, err)
+		assert.Equal(t, string(c.New), string(data))
+	}
+
+	backup, err := os.ReadFile(files[0] + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, editNotesContents, string(backup))
+	assert.NoFileExists(t, to+".bak")
+}
+
+func TestWriteFileChangesDiff(t *testing.T) {
+	t.Parallel()
+
+	files := writeEditNotesFiles(t, editNotesContents)
+	to := filepath.Join(t.TempDir(), "new.md")
+	changes, err := fcqs.MoveNote(files, newTitle(t, "target"), to)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = fcqs.WriteFileChangesDiff(&buf, changes)
+
+	require.NoError(t, err)
+	assert.Equal(t, "--- "+os.DevNull+"\n+++ "+to+"\n@@ -0,0 +1,3 @@\n+## target\n+\n+target contents\n"+
+		"--- "+files[0]+"\n+++ "+files[0]+"\n@@ -4,9 +4,5 @@\n # target\n ```\n \n-## target\n-\n-target contents\n-\n # last\n last contents\n", buf.String())
+}
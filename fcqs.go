@@ -8,6 +8,7 @@ import (
 	"os"
 	"slices"
 	"strings"
+	"sync"
 
 	"github.com/yendo/fcqs/internal/value"
 	"mvdan.cc/xurls/v2"
@@ -16,8 +17,6 @@ import (
 const (
 	DefaultNotesFile = "fcnotes.md"
 
-	shellPrompt = "$"
-
 	// State of text line.
 	normal = iota
 	fenced
@@ -27,166 +26,329 @@ const (
 
 // WriteTitles writes the titles of all notes.
 func WriteTitles(w io.Writer, r io.Reader) error {
-	var allTitles []value.Title
-	var title value.Title
+	nb, err := parseNotebook(bufio.NewScanner(r), "")
+	if err != nil {
+		return fmt.Errorf("seek titles: %w", err)
+	}
 
-	scanner := bufio.NewScanner(r)
-	state := normal
+	for title := range nb.Titles() {
+		fmt.Fprintln(w, title)
+	}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
-		}
+	return nil
+}
 
-		switch state {
-		case normal:
-			if value.IsFenceLine(line) {
-				state = fenced
-			} else if tl, ok := value.NewTitleLine(line); ok && tl.HasValidTitle() {
-				title = tl.Title()
-				continue
-			}
+// WriteContents writes the contents of the note.
+func WriteContents(w io.Writer, r io.Reader, title *value.Title, isNoTitle bool) error {
+	nb, err := parseNotebook(bufio.NewScanner(r), "")
+	if err != nil {
+		return fmt.Errorf("seek contents: %w", err)
+	}
 
-			if !slices.Contains(allTitles, title) {
-				fmt.Fprintln(w, title)
-				allTitles = append(allTitles, title)
-			}
+	f := newFilter(w, isNoTitle)
+	defer f.Close()
 
-		case fenced:
-			if value.IsFenceLine(line) {
-				state = normal
-			}
-		}
+	for note := range nb.find(title) {
+		note.writeTo(f)
 	}
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("seek titles: %w", err)
+
+	return nil
+}
+
+// WriteFirstURL writes the first URL in the contents of the note.
+func WriteFirstURL(w io.Writer, r io.Reader, title *value.Title) error {
+	var buf bytes.Buffer
+	if err := WriteContents(&buf, r, title, false); err != nil {
+		return err
 	}
+	writeFirstURL(w, buf.String())
 
 	return nil
 }
 
-// WriteContents writes the contents of the note.
-func WriteContents(w io.Writer, r io.Reader, title *value.Title, isNoTitle bool) error {
-	f := newFilter(w, isNoTitle)
-	defer f.Close()
+// rxStrict is compiled once since compiling the URL pattern is expensive.
+var rxStrict = sync.OnceValue(xurls.Strict)
+
+// writeFirstURL writes the first URL in the contents.
+func writeFirstURL(w io.Writer, contents string) {
+	if url := rxStrict().FindString(contents); url != "" {
+		fmt.Fprintln(w, url)
+	}
+}
+
+// newScanner is to replace bufio.NewScanner for test.
+var newScanner = bufio.NewScanner
+
+// WriteFirstCmdLineBlock writes the first command-line block for the shell in the contents of the note.
+// The block for any shell is written if the shell is empty.
+func WriteFirstCmdLineBlock(w io.Writer, r io.Reader, title *value.Title, shell string) error {
+	var buf bytes.Buffer
+	if err := WriteContents(&buf, r, title, false); err != nil {
+		return err
+	}
+
+	return writeFirstCmdLineBlock(w, &buf, shell)
+}
+
+// writeFirstCmdLineBlock writes the first command-line block for the shell in the contents.
+func writeFirstCmdLineBlock(w io.Writer, contents io.Reader, shell string) error {
+	blocks, err := CmdLineBlocks(contents)
+	if err != nil {
+		return err
+	}
+
+	if block, ok := FirstCmdLineBlock(CmdLineBlocksForShell(blocks, shell), shell); ok {
+		fmt.Fprint(w, block.Command)
+	}
+
+	return nil
+}
+
+// FirstCmdLineBlock returns the first command-line block for the shell in the blocks.
+// A block for the shell itself is preferred to the generic blocks before it.
+func FirstCmdLineBlock(blocks []CmdLineBlock, shell string) (CmdLineBlock, bool) {
+	if len(blocks) == 0 {
+		return CmdLineBlock{}, false
+	}
+
+	if i := slices.IndexFunc(blocks, func(b CmdLineBlock) bool { return b.Language == shell }); i >= 0 {
+		return blocks[i], true
+	}
+
+	return blocks[0], true
+}
+
+// CmdLineBlock represents a command-line block in the contents of a note.
+type CmdLineBlock struct {
+	// Label is the last line of the prose before the block.
+	Label   string `json:"label"`
+	Command string `json:"command"`
+	// Language is the shell language of the fence, which is sh, bash, zsh, fish or pwsh.
+	Language string `json:"language"`
+}
+
+// newCmdLineBlock returns the command-line block with the label in the fence.
+// The commands of console transcripts are taken from the lines with prompts.
+func newCmdLineBlock(label, command string, fence *value.FenceLine) CmdLineBlock {
+	if fence.IsTranscript() {
+		command = transcriptCommand(command)
+	}
+	if label == "" {
+		label, _, _ = strings.Cut(command, "\n")
+	}
+
+	return CmdLineBlock{Label: label, Command: command, Language: fence.Language()}
+}
+
+// CmdLineBlocksForShell returns the command-line blocks for the shell and the generic blocks for sh
+// in order of appearance, so that the numbers of the blocks follow the note.
+// PowerShell has no generic blocks, and all blocks are returned if the shell is empty.
+func CmdLineBlocksForShell(blocks []CmdLineBlock, shell string) []CmdLineBlock {
+	if shell == "" {
+		return blocks
+	}
+
+	var matched []CmdLineBlock
+	for _, block := range blocks {
+		if block.Language == shell || (block.Language == value.GenericShell && shell != "pwsh") {
+			matched = append(matched, block)
+		}
+	}
+
+	return matched
+}
 
+// CmdLineBlocks returns all command-line blocks in the contents of a note.
+// The label of a block is the first line of the command if no prose precedes the block.
+func CmdLineBlocks(contents io.Reader) ([]CmdLineBlock, error) {
+	var blocks []CmdLineBlock
+	var fence *value.FenceLine
+	var label string
+	var cmd strings.Builder
 	state := normal
 
-	scanner := bufio.NewScanner(r)
+	scanner := newLineScanner(newScanner(contents))
 	for scanner.Scan() {
 		line := scanner.Text()
 
 		switch state {
 		case normal:
-			if value.IsFenceLine(line) {
+			if fl, ok := value.NewFenceLine(line); ok {
+				fence = fl
 				state = fenced
-			} else if tl, ok := value.NewTitleLine(line); ok && tl.EqualTitle(title) {
-				state = scoped
-				fmt.Fprint(f, line)
+				if fl.HasShellID() {
+					state = scopedFenced
+				}
+			} else if tl, _, ok := scanner.scanTitleLine(); ok && tl.HasValidTitle() {
+				label = tl.Title().String()
+			} else if l := strings.TrimSpace(line); l != "" {
+				label = l
 			}
 
 		case fenced:
-			if value.IsFenceLine(line) {
+			if fence.IsClosedBy(line) {
 				state = normal
 			}
 
-		case scoped:
-			if value.IsFenceLine(line) {
-				state = scopedFenced
-			} else if tl, ok := value.NewTitleLine(line); ok && !tl.EqualTitle(title) {
-				state = normal
+		case scopedFenced:
+			if !fence.IsClosedBy(line) {
+				cmd.WriteString(line + "\n")
 				break
 			}
 
-			fmt.Fprint(f, line)
-
-		case scopedFenced:
-			if value.IsFenceLine(line) {
-				state = scoped
-			}
-			fmt.Fprint(f, line)
+			blocks = append(blocks, newCmdLineBlock(label, cmd.String(), fence))
+			label = ""
+			cmd.Reset()
+			state = normal
 		}
 	}
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("seek contents: %w", err)
+		return nil, fmt.Errorf("seek command line block: %w", err)
 	}
 
-	return nil
-}
-
-// WriteFirstURL writes the first URL in the contents of the note.
-func WriteFirstURL(w io.Writer, r io.Reader, title *value.Title) error {
-	var buf bytes.Buffer
-	if err := WriteContents(&buf, r, title, false); err != nil {
-		return err
-	}
-
-	rxStrict := xurls.Strict()
-
-	if url := rxStrict.FindString(buf.String()); url != "" {
-		fmt.Fprintln(w, url)
+	// A block not closed until the end of the note is a command-line block.
+	if state == scopedFenced {
+		blocks = append(blocks, newCmdLineBlock(label, cmd.String(), fence))
 	}
 
-	return nil
+	return blocks, nil
 }
 
-// newScanner is to replace bufio.NewScanner for test.
-var newScanner = bufio.NewScanner
+// CodeBlock represents a fenced code block in the contents of a note.
+type CodeBlock struct {
+	// Language is the identifier of the fence, which is empty if the fence has no info string.
+	Language string `json:"language"`
+	Code     string `json:"code"`
+}
 
-// WriteFirstCmdLineBlock writes the first command-line block in the contents of the note.
-func WriteFirstCmdLineBlock(w io.Writer, r io.Reader, title *value.Title) error {
+// CodeBlocks returns all fenced code blocks in the contents of a note.
+func CodeBlocks(contents io.Reader) ([]CodeBlock, error) {
+	var blocks []CodeBlock
+	var fence *value.FenceLine
+	var code strings.Builder
 	state := normal
 
-	var buf bytes.Buffer
-	if err := WriteContents(&buf, r, title, false); err != nil {
-		return err
-	}
-	scanner := newScanner(&buf)
-
-loop:
+	scanner := newLineScanner(newScanner(contents))
 	for scanner.Scan() {
 		line := scanner.Text()
-		fenceLine, isFenceLine := value.NewFenceLine(line)
 
 		switch state {
 		case normal:
-			if isFenceLine && fenceLine.HasShellID() {
+			if fl, ok := value.NewFenceLine(line); ok {
+				fence = fl
 				state = fenced
 			}
 
 		case fenced:
-			if isFenceLine {
-				break loop
+			if !fence.IsClosedBy(line) {
+				code.WriteString(line + "\n")
+				break
 			}
-			fmt.Fprintln(w, strings.TrimLeft(line, shellPrompt+" "))
+
+			blocks = append(blocks, CodeBlock{Language: fence.ID(), Code: code.String()})
+			code.Reset()
+			state = normal
 		}
 	}
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("seek command line block: %w", err)
+		return nil, fmt.Errorf("seek code blocks: %w", err)
 	}
 
-	return nil
+	// A block not closed until the end of the note is a code block.
+	if state == fenced {
+		blocks = append(blocks, CodeBlock{Language: fence.ID(), Code: code.String()})
+	}
+
+	return blocks, nil
 }
 
 // WriteNoteLocation writes the file name and line number of the note.
 func WriteNoteLocation(w io.Writer, files []*os.File, title *value.Title) error {
+	locations, err := NoteLocations(files, title)
+	if err != nil {
+		return err
+	}
+
+	writeLocations(w, locations)
+
+	return nil
+}
+
+// Location represents the position of a title line in the notes files.
+type Location struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// writeLocations writes the quoted file names and the line numbers of the locations.
+func writeLocations(w io.Writer, locations []Location) {
+	for _, l := range locations {
+		fmt.Fprintf(w, "%q %d\n", l.File, l.Line)
+	}
+}
+
+// NoteLocations returns the first location of the note in each file.
+func NoteLocations(files []*os.File, title *value.Title) ([]Location, error) {
+	var locations []Location
+
 	for _, file := range files {
-		c := 0
-		scanner := newScanner(file)
+		nb, err := parseNotebook(newScanner(file), file.Name())
+		if err != nil {
+			return nil, fmt.Errorf("seek note location: %w", err)
+		}
+
+		for note := range nb.find(title) {
+			locations = append(locations, Location{File: note.File, Line: note.Line})
+			break
+		}
+	}
+
+	return locations, nil
+}
+
+// TitleLocation represents the title of a note and the location of its title line.
+type TitleLocation struct {
+	Title string `json:"title"`
+	Location
+}
+
+// TitleLocations returns the titles of all title lines with their locations in order of appearance.
+// The titles are the title paths if hierarchical is true.
+func TitleLocations(files []*os.File, hierarchical bool) ([]TitleLocation, error) {
+	var locations []TitleLocation
+
+	for _, file := range files {
+		var stack headingStack
+		var fence *value.FenceLine
+		state := normal
+		scanner := newLineScanner(newScanner(file))
 
 		for scanner.Scan() {
-			c++
 			line := scanner.Text()
+			c := scanner.LineNumber()
 
-			if tl, ok := value.NewTitleLine(line); ok && tl.EqualTitle(title) {
-				fmt.Fprintf(w, "%q %d\n", file.Name(), c)
-				break
+			if state == fenced {
+				if fence.IsClosedBy(line) {
+					state = normal
+				}
+				continue
+			}
+
+			if fl, ok := value.NewFenceLine(line); ok {
+				fence = fl
+				state = fenced
+			} else if tl, _, ok := scanner.scanTitleLine(); ok {
+				stack = stack.push(tl)
+				if title, ok := stack.title(hierarchical); ok {
+					location := Location{File: file.Name(), Line: c}
+					locations = append(locations, TitleLocation{Title: title, Location: location})
+				}
 			}
 		}
 		if err := scanner.Err(); err != nil {
-			return fmt.Errorf("seek note location: %w", err)
+			return nil, fmt.Errorf("seek titles: %w", err)
 		}
 	}
-	return nil
+
+	return locations, nil
 }
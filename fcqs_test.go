@@ -85,6 +85,9 @@ func TestWriteContents(t *testing.T) {
 		{"# URL\n", "fcqs: http://github.com/yendo/fcqs/\n" + "github: http://github.com/\n"},
 		{"# command-line\n", "```sh\n" + "ls -l | nl\n" + "```\n"},
 		{"# command-line with $\n", "```console\n" + "$ date\n" + "```\n"},
+		{"# Headings in tilde fenced code blocks are ignored\n", "~~~\n" + "# tilde fenced heading\n" + "```\n" + "~~~\n"},
+		{"# Fences are closed by a fence of the same char and enough length\n", "````markdown\n" + "```sh\n" +
+			"# nested fenced heading\n" + "```\n" + "````\n"},
 	}
 
 	t.Run("contents with title", func(t *testing.T) {
@@ -130,6 +133,41 @@ func TestWriteContents(t *testing.T) {
 	})
 }
 
+func TestWriteSetextContents(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		title    string
+		heading  string
+		contents string
+	}{
+		{"Setext heading with equals", "Setext heading with equals\n==========================\n", "contents\n"},
+		{"Setext heading with dashes", "Setext heading with dashes\n---\n", "contents\n\n---\n\n" +
+			"A line of dashes after a blank line is not a heading.\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			title, err := value.NewTitle(tc.title)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = fcqs.WriteContents(&buf, openTestNotesFile(t, test.NotesFile), title, false)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.heading+"\n"+tc.contents, buf.String())
+
+			buf.Reset()
+			err = fcqs.WriteContents(&buf, openTestNotesFile(t, test.NotesFile), title, true)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.contents, buf.String())
+		})
+	}
+}
+
 func TestWriteNoContents(t *testing.T) {
 	t.Parallel()
 
@@ -233,6 +271,7 @@ func TestWriteFirstCmdLine(t *testing.T) {
 		{"go", false},
 		{"no identifier", false},
 		{"other identifier", false},
+		{"tilde", true},
 	}
 
 	for _, tc := range tests {
@@ -244,7 +283,7 @@ func TestWriteFirstCmdLine(t *testing.T) {
 			require.NoError(t, err)
 
 			var buf bytes.Buffer
-			err = fcqs.WriteFirstCmdLineBlock(&buf, file, title)
+			err = fcqs.WriteFirstCmdLineBlock(&buf, file, title, "")
 
 			require.NoError(t, err)
 			expected := map[bool]string{true: "ls -l | nl\n", false: ""}
@@ -259,12 +298,32 @@ func TestWriteFirstCmdLine(t *testing.T) {
 		require.NoError(t, err)
 
 		var buf bytes.Buffer
-		err = fcqs.WriteFirstCmdLineBlock(&buf, file, title)
+		err = fcqs.WriteFirstCmdLineBlock(&buf, file, title, "")
 
 		require.NoError(t, err)
 		assert.Equal(t, "ls -l | nl\n", buf.String())
 	})
 
+	t.Run("output first command line block for shell", func(t *testing.T) {
+		t.Parallel()
+
+		title, err := value.NewTitle("cross shell")
+		require.NoError(t, err)
+
+		for shell, expected := range map[string]string{
+			"":     "Get-ChildItem -Force\n",
+			"bash": "ls -a\n",
+			"zsh":  "ls -A\n",
+			"pwsh": "Get-ChildItem -Force\n",
+		} {
+			var buf bytes.Buffer
+			err = fcqs.WriteFirstCmdLineBlock(&buf, openTestNotesFile(t, test.ShellBlockFile), title, shell)
+
+			require.NoError(t, err)
+			assert.Equal(t, expected, buf.String(), shell)
+		}
+	})
+
 	t.Run("scan error to seek contents", func(t *testing.T) {
 		t.Parallel()
 
@@ -273,7 +332,7 @@ func TestWriteFirstCmdLine(t *testing.T) {
 		require.NoError(t, err)
 
 		var buf bytes.Buffer
-		err = fcqs.WriteFirstCmdLineBlock(&buf, r, title)
+		err = fcqs.WriteFirstCmdLineBlock(&buf, r, title, "")
 
 		require.EqualError(t, err, fmt.Sprintf("seek contents: %s", ErrScanForTest))
 		assert.Empty(t, buf.String())
@@ -287,13 +346,205 @@ func TestWriteFirstCmdLine(t *testing.T) {
 		require.NoError(t, err)
 
 		var buf bytes.Buffer
-		err = fcqs.WriteFirstCmdLineBlock(&buf, file, title)
+		err = fcqs.WriteFirstCmdLineBlock(&buf, file, title, "")
 
 		require.EqualError(t, err, fmt.Sprintf("seek command line block: %s", ErrScanForTest))
 		assert.Empty(t, buf.String())
 	})
 }
 
+func TestCmdLineBlocks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("blocks with labels", func(t *testing.T) {
+		t.Parallel()
+
+		file := openTestNotesFile(t, test.ShellBlockFile)
+		title, err := value.NewTitle("steps")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = fcqs.WriteContents(&buf, file, title, false)
+		require.NoError(t, err)
+
+		blocks, err := fcqs.CmdLineBlocks(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, []fcqs.CmdLineBlock{
+			{Label: "Install the package:", Command: "sudo apt install jq\n", Language: "sh"},
+			{Label: "jq --version", Command: "jq --version\n", Language: "sh"},
+			{Label: "More details.", Command: "jq . config.json\n", Language: "sh"},
+		}, blocks)
+	})
+
+	tests := []struct {
+		name     string
+		contents string
+		expect   []fcqs.CmdLineBlock
+	}{
+		{
+			name:     "title label",
+			contents: "# title\n\n```sh\nls\n```\n",
+			expect:   []fcqs.CmdLineBlock{{Label: "title", Command: "ls\n", Language: "sh"}},
+		},
+		{
+			name:     "setext title label",
+			contents: "title\n=====\n```sh\nls\n```\n",
+			expect:   []fcqs.CmdLineBlock{{Label: "title", Command: "ls\n", Language: "sh"}},
+		},
+		{
+			name:     "multiple lines",
+			contents: "```sh\ncd /tmp\nls\n```\n",
+			expect:   []fcqs.CmdLineBlock{{Label: "cd /tmp", Command: "cd /tmp\nls\n", Language: "sh"}},
+		},
+		{
+			name:     "shell block in other block",
+			contents: "````md\n```sh\nls\n```\n````\n",
+			expect:   nil,
+		},
+		{
+			name:     "not closed block",
+			contents: "run\n```sh\nls\n",
+			expect:   []fcqs.CmdLineBlock{{Label: "run", Command: "ls\n", Language: "sh"}},
+		},
+		{
+			name:     "powershell",
+			contents: "```pwsh\nGet-ChildItem\n```\n",
+			expect:   []fcqs.CmdLineBlock{{Label: "Get-ChildItem", Command: "Get-ChildItem\n", Language: "pwsh"}},
+		},
+		{
+			name:     "no blocks",
+			contents: "# title\n\ncontents\n",
+			expect:   nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			blocks, err := fcqs.CmdLineBlocks(strings.NewReader(tc.contents))
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, blocks)
+		})
+	}
+}
+
+func TestCmdLineBlocksForShell(t *testing.T) {
+	t.Parallel()
+
+	blocks := []fcqs.CmdLineBlock{
+		{Label: "pwsh", Command: "Get-ChildItem\n", Language: "pwsh"},
+		{Label: "sh", Command: "ls -A\n", Language: "sh"},
+		{Label: "bash", Command: "ls -a\n", Language: "bash"},
+	}
+
+	tests := []struct {
+		shell  string
+		expect []string
+	}{
+		{shell: "", expect: []string{"pwsh", "sh", "bash"}},
+		{shell: "bash", expect: []string{"sh", "bash"}},
+		{shell: "zsh", expect: []string{"sh"}},
+		{shell: "pwsh", expect: []string{"pwsh"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.shell, func(t *testing.T) {
+			t.Parallel()
+
+			var labels []string
+			for _, block := range fcqs.CmdLineBlocksForShell(blocks, tc.shell) {
+				labels = append(labels, block.Label)
+			}
+
+			assert.Equal(t, tc.expect, labels)
+		})
+	}
+}
+
+func TestFirstCmdLineBlock(t *testing.T) {
+	t.Parallel()
+
+	blocks := []fcqs.CmdLineBlock{
+		{Label: "one", Command: "echo one\n", Language: "sh"},
+		{Label: "two", Command: "echo two\n", Language: "bash"},
+		{Label: "three", Command: "echo three\n", Language: "sh"},
+	}
+
+	tests := []struct {
+		shell  string
+		expect string
+	}{
+		{shell: "bash", expect: "two"},
+		{shell: "zsh", expect: "one"},
+		{shell: "", expect: "one"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.shell, func(t *testing.T) {
+			t.Parallel()
+
+			block, ok := fcqs.FirstCmdLineBlock(fcqs.CmdLineBlocksForShell(blocks, tc.shell), tc.shell)
+
+			require.True(t, ok)
+			assert.Equal(t, tc.expect, block.Label)
+		})
+	}
+
+	_, ok := fcqs.FirstCmdLineBlock(nil, "bash")
+	assert.False(t, ok)
+}
+
+func TestCodeBlocks(t *testing.T) {
+	t.Parallel()
+
+	contents := "```go\nfmt.Println()\n```\n\n~~~\n# not a title\n~~~\n\n```sh\nls\n"
+
+	blocks, err := fcqs.CodeBlocks(strings.NewReader(contents))
+
+	require.NoError(t, err)
+	assert.Equal(t, []fcqs.CodeBlock{
+		{Language: "go", Code: "fmt.Println()\n"},
+		{Language: "", Code: "# not a title\n"},
+		{Language: "sh", Code: "ls\n"},
+	}, blocks)
+}
+
+func TestTitleLocations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("titles", func(t *testing.T) {
+		t.Parallel()
+
+		file := openTestNotesFile(t, test.LocationFile)
+
+		locations, err := fcqs.TitleLocations([]*os.File{file}, false)
+
+		require.NoError(t, err)
+		assert.Contains(t, locations, fcqs.TitleLocation{Title: "5th Line", Location: fcqs.Location{File: file.Name(), Line: 5}})
+	})
+
+	t.Run("title paths", func(t *testing.T) {
+		t.Parallel()
+
+		file := openTestNotesFile(t, test.HierarchyFile)
+
+		locations, err := fcqs.TitleLocations([]*os.File{file}, true)
+
+		require.NoError(t, err)
+		assert.Equal(t, []fcqs.TitleLocation{
+			{Title: "parent", Location: fcqs.Location{File: file.Name(), Line: 1}},
+			{Title: "parent/child", Location: fcqs.Location{File: file.Name(), Line: 5}},
+			{Title: "parent/child/grandchild", Location: fcqs.Location{File: file.Name(), Line: 13}},
+		}, locations[:3])
+		// The title lines of setext headings are the lines of the text.
+		assert.Contains(t, locations, fcqs.TitleLocation{Title: "sections only", Location: fcqs.Location{File: file.Name(), Line: 27}})
+		assert.Contains(t, locations, fcqs.TitleLocation{Title: "sections only/section", Location: fcqs.Location{File: file.Name(), Line: 30}})
+	})
+}
+
 func TestWriteNoteLocation(t *testing.T) {
 	// Cannot run tests in parallel due to fcqs.SetNewScannerMock
 
@@ -332,6 +583,34 @@ func TestWriteNoteLocation(t *testing.T) {
 		assert.Equal(t, fmt.Sprintf("%q 9\n", testFile2.Name()), buf.String())
 	})
 
+	t.Run("setext heading", func(t *testing.T) {
+		t.Parallel()
+
+		testFile := openTestNotesFile(t, test.NotesFile)
+		title, err := value.NewTitle("Setext heading with dashes")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = fcqs.WriteNoteLocation(&buf, []*os.File{testFile}, title)
+
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("%q 123\n", testFile.Name()), buf.String())
+	})
+
+	t.Run("headings in fenced code blocks are ignored", func(t *testing.T) {
+		t.Parallel()
+
+		testFile := openTestNotesFile(t, test.NotesFile)
+		title, err := value.NewTitle("fenced heading")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = fcqs.WriteNoteLocation(&buf, []*os.File{testFile}, title)
+
+		require.NoError(t, err)
+		assert.Empty(t, buf.String())
+	})
+
 	t.Run("scan error", func(t *testing.T) {
 		fcqs.SetNewScannerMock(t, ErrScanForTest)
 
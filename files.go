@@ -1,17 +1,49 @@
 package fcqs
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
+const (
+	globStar = "**"
+
+	// projectNotesDir is the directory of notes files in a project.
+	projectNotesDir = ".fcqs"
+)
+
+// Sources of notes files.
+const (
+	SourceProject = "project"
+	SourceGlobal  = "global"
+)
+
+var (
+	// notesFileExts are the extensions of notes files in directories.
+	notesFileExts = []string{".md", ".markdown"}
+
+	// defaultIgnorePatterns are the names of files and directories that are not searched for notes files.
+	defaultIgnorePatterns = []string{".git", ".hg", ".svn", "node_modules"}
+
+	// repositoryMarkers are the names of directories or files at the root of a repository.
+	repositoryMarkers = []string{".git", ".hg", ".svn"}
+)
+
+// ErrNotNotesFile is returned when a notes file is a directory or a glob pattern instead of a file.
+var ErrNotNotesFile = errors.New("not a notes file")
+
 // NotesFiles represents notes files.
 type NotesFiles struct {
 	Reader io.Reader
 	Files  []*os.File
+	// Sources are the sources of the files, which are SourceProject or SourceGlobal.
+	Sources []string
 }
 
 // Close closes all notes files.
@@ -23,38 +55,76 @@ func (n NotesFiles) Close() {
 
 // NewNOtesFiles returns NotesFiles instance.
 func OpenNotesFiles() (*NotesFiles, error) {
-	fileName, err := notesFileNames()
+	return OpenNotesFilesWithConfig(NotesConfig{})
+}
+
+// OpenNotesFilesWithConfig returns NotesFiles instance with the config.
+// The notes files are taken from environment variables, the config or the default notes file,
+// following the notes files of the project in the working directory if cfg.Project is true.
+func OpenNotesFilesWithConfig(cfg NotesConfig) (*NotesFiles, error) {
+	return openNotesFiles(cfg, false)
+}
+
+// OpenExistingNotesFiles returns NotesFiles instance like OpenNotesFilesWithConfig, but skips the notes files that do not exist,
+// such as the file to add notes to before the first note is added.
+func OpenExistingNotesFiles(cfg NotesConfig) (*NotesFiles, error) {
+	return openNotesFiles(cfg, true)
+}
+
+// openNotesFiles opens the notes files, skipping missing files if skipMissing is true.
+func openNotesFiles(cfg NotesConfig, skipMissing bool) (*NotesFiles, error) {
+	projectFileName, err := projectNotesFileNames(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("project notes file name: %w", err)
+	}
+
+	fileName, err := notesFileNames(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("notes file name: %w", err)
+	}
+
+	// The project notes files are existing files, which are kept at the head.
+	fileName, err = expandNotesFileNames(slices.Concat(projectFileName, fileName), ignorePatterns(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("notes file name: %w", err)
 	}
 
 	readers := make([]io.Reader, 0, len(fileName))
 	files := make([]*os.File, 0, len(fileName))
+	sources := make([]string, 0, len(fileName))
 
-	for _, v := range fileName {
+	for i, v := range fileName {
 		file, err := os.Open(v)
+		if skipMissing && errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
 		if err != nil {
 			return nil, fmt.Errorf("notes file: %w", err)
 		}
 		readers = append(readers, file)
 		files = append(files, file)
+
+		source := SourceGlobal
+		if i < len(projectFileName) {
+			source = SourceProject
+		}
+		sources = append(sources, source)
 	}
 
 	reader := io.MultiReader(readers...)
 
-	return &NotesFiles{Reader: reader, Files: files}, nil
+	return &NotesFiles{Reader: reader, Files: files, Sources: sources}, nil
 }
 
 // notesFileNames returns filenames of notes.
-func notesFileNames() ([]string, error) {
+func notesFileNames(cfg NotesConfig) ([]string, error) {
 	f := os.Getenv("FCQS_NOTES_FILES")
 	if f == "" {
 		f = os.Getenv("FCQS_NOTES_FILE")
 	}
 
 	if f != "" {
-		fileNames := strings.Split(f, string(os.PathListSeparator))
-		return fileNames, nil
+		return splitList(f), nil
 	}
 
 	home, err := os.UserHomeDir()
@@ -62,6 +132,314 @@ func notesFileNames() ([]string, error) {
 		return nil, fmt.Errorf("user home directory: %w", err)
 	}
 
+	if len(cfg.Files) > 0 {
+		// The home directory is expanded since the config file is not processed by shells.
+		fileNames := make([]string, 0, len(cfg.Files))
+		for _, f := range cfg.Files {
+			if rest, ok := strings.CutPrefix(f, "~/"); ok {
+				f = filepath.Join(home, rest)
+			}
+			fileNames = append(fileNames, f)
+		}
+		return fileNames, nil
+	}
+
 	filenames := []string{filepath.Join(home, DefaultNotesFile)}
 	return filenames, nil
 }
+
+// NotesFileToAdd returns the notes file to add notes to, which is the first notes file other than the project notes.
+// The file does not need to exist, but it must not be a directory or a glob pattern.
+func NotesFileToAdd(cfg NotesConfig) (string, error) {
+	fileNames, err := notesFileNames(cfg)
+	if err != nil {
+		return "", fmt.Errorf("notes file name: %w", err)
+	}
+
+	name := fileNames[0]
+	if info, err := os.Stat(name); (err == nil && info.IsDir()) || (err != nil && hasGlobMeta(name)) {
+		return "", fmt.Errorf("%w: %s", ErrNotNotesFile, name)
+	}
+
+	return name, nil
+}
+
+// projectNotesFileNames returns the notes files of the project in the working directory.
+// They are fcnotes.md and the notes files in .fcqs of the directories
+// from the working directory up to the repository root, where nearer directories come first.
+func projectNotesFileNames(cfg NotesConfig) ([]string, error) {
+	if !cfg.Project {
+		return nil, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("working directory: %w", err)
+	}
+
+	// Errors of the home directory are reported with the global notes files.
+	home, _ := os.UserHomeDir()
+
+	var fileNames []string
+	for _, dir := range projectDirs(wd, home) {
+		if info, err := os.Stat(filepath.Join(dir, DefaultNotesFile)); err == nil && info.Mode().IsRegular() {
+			fileNames = append(fileNames, filepath.Join(dir, DefaultNotesFile))
+		}
+
+		entries, err := os.ReadDir(filepath.Join(dir, projectNotesDir))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("project notes directory: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.Type().IsRegular() && isNotesFile(entry.Name()) {
+				fileNames = append(fileNames, filepath.Join(dir, projectNotesDir, entry.Name()))
+			}
+		}
+	}
+
+	return fileNames, nil
+}
+
+// projectDirs returns the directories from the directory up to the repository root.
+// Only the directory is returned outside repositories, and
+// the home directory is not a project since its notes file is the global one.
+func projectDirs(dir, home string) []string {
+	var dirs []string
+
+	for d := dir; d != home; d = filepath.Dir(d) {
+		dirs = append(dirs, d)
+		if slices.ContainsFunc(repositoryMarkers, func(m string) bool {
+			_, err := os.Stat(filepath.Join(d, m))
+			return err == nil
+		}) {
+			return dirs
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	return dirs[:1]
+}
+
+// ignorePatterns returns the patterns of names ignored in directories and glob patterns.
+// FCQS_NOTES_IGNORE takes precedence over the config.
+func ignorePatterns(cfg NotesConfig) []string {
+	if p := os.Getenv("FCQS_NOTES_IGNORE"); p != "" {
+		return splitList(p)
+	}
+
+	if cfg.Ignore != nil {
+		return cfg.Ignore
+	}
+
+	return defaultIgnorePatterns
+}
+
+// expandNotesFileNames returns the filenames with directories and glob patterns expanded.
+// The notes files in a directory are found recursively in lexical order, and matches of a glob pattern are sorted.
+// A filename appearing more than once is returned at the first appearance.
+func expandNotesFileNames(names []string, ignores []string) ([]string, error) {
+	var fileNames []string
+	seen := make(map[string]bool)
+
+	for _, name := range names {
+		var matches []string
+		var err error
+
+		// An existing file is not a glob pattern even if its name has special characters.
+		info, statErr := os.Stat(name)
+		switch {
+		case statErr == nil && info.IsDir():
+			matches, err = walkNotesDir(name, ignores)
+		case statErr != nil && hasGlobMeta(name):
+			matches, err = globNotesFiles(name, ignores)
+		default:
+			// Errors of a file are reported when the file is opened.
+			matches = []string{name}
+		}
+		if err != nil {
+			return nil, err
+		}
+		// A typo in a directory or a glob pattern is reported as well as a missing file.
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%w: %s", fs.ErrNotExist, name)
+		}
+
+		for _, m := range matches {
+			if key := filepath.Clean(m); !seen[key] {
+				seen[key] = true
+				fileNames = append(fileNames, m)
+			}
+		}
+	}
+
+	return fileNames, nil
+}
+
+// hasGlobMeta reports whether the name has special characters of glob patterns.
+func hasGlobMeta(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// isIgnored reports whether the base name matches any of the ignore patterns.
+func isIgnored(name string, ignores []string) bool {
+	return slices.ContainsFunc(ignores, func(p string) bool {
+		ok, _ := filepath.Match(p, filepath.Base(name))
+		return ok
+	})
+}
+
+// isNotesFile reports whether the file in a directory is a notes file.
+func isNotesFile(name string) bool {
+	return slices.Contains(notesFileExts, strings.ToLower(filepath.Ext(name)))
+}
+
+// walkNotesDir returns the notes files in the directory and its subdirectories.
+func walkNotesDir(dir string, ignores []string) ([]string, error) {
+	var fileNames []string
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && isIgnored(path, ignores) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.Type().IsRegular() && isNotesFile(path) {
+			fileNames = append(fileNames, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("notes directory: %w", err)
+	}
+
+	return fileNames, nil
+}
+
+// globNotesFiles returns the files matching the glob pattern in lexical order.
+// ** matches zero or more directories, and matching directories are walked for notes files.
+func globNotesFiles(pattern string, ignores []string) ([]string, error) {
+	root, rest := splitGlobRoot(pattern)
+	// A pattern under a missing directory matches nothing as well as filepath.Glob.
+	if _, err := os.Stat(root); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	var fileNames []string
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		if isIgnored(path, ignores) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		elems := strings.Split(rel, string(filepath.Separator))
+		if !matchGlob(rest, elems) {
+			// Directories that cannot lead to matches are not walked.
+			if d.IsDir() && !matchGlobPrefix(rest, elems) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !d.IsDir() {
+			fileNames = append(fileNames, path)
+			return nil
+		}
+
+		files, err := walkNotesDir(path, ignores)
+		if err != nil {
+			return err
+		}
+		fileNames = append(fileNames, files...)
+
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("notes glob pattern: %w", err)
+	}
+
+	return fileNames, nil
+}
+
+// splitGlobRoot splits the glob pattern into the directory without special characters and the rest elements.
+func splitGlobRoot(pattern string) (string, []string) {
+	elems := strings.Split(filepath.ToSlash(pattern), "/")
+
+	i := slices.IndexFunc(elems, hasGlobMeta)
+	root := filepath.FromSlash(strings.Join(elems[:i], "/"))
+	switch {
+	case i == 0:
+		root = "."
+	case root == "":
+		root = string(filepath.Separator)
+	}
+
+	return root, elems[i:]
+}
+
+// matchGlob reports whether the path elements match the pattern elements.
+func matchGlob(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+
+	if pattern[0] == globStar {
+		for i := 0; i <= len(path); i++ {
+			if matchGlob(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+	if ok, _ := filepath.Match(pattern[0], path[0]); !ok {
+		return false
+	}
+
+	return matchGlob(pattern[1:], path[1:])
+}
+
+// matchGlobPrefix reports whether paths under the path elements can match the pattern elements.
+func matchGlobPrefix(pattern, path []string) bool {
+	switch {
+	case len(path) == 0:
+		return true
+	case len(pattern) == 0:
+		return false
+	case pattern[0] == globStar:
+		return true
+	}
+
+	if ok, _ := filepath.Match(pattern[0], path[0]); !ok {
+		return false
+	}
+
+	return matchGlobPrefix(pattern[1:], path[1:])
+}
@@ -1,6 +1,7 @@
 package fcqs_test
 
 import (
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
@@ -77,6 +78,20 @@ func TestNewNotesFiles(t *testing.T) {
 		assert.Equal(t, expectedFileNames[2], notes.Files[2].Name())
 	})
 
+	t.Run("set files from config", func(t *testing.T) {
+		t.Setenv("FCQS_NOTES_FILE", "")
+		t.Setenv("FCQS_NOTES_FILES", "")
+
+		t.Setenv("HOME", filepath.Dir(test.LocationExtraFile))
+
+		notes, err := fcqs.OpenNotesFilesWithConfig(fcqs.NotesConfig{Files: []string{test.LocationFile, "~/" + filepath.Base(test.LocationExtraFile)}})
+		notes.Close()
+
+		require.NoError(t, err)
+		assert.Equal(t, test.LocationFile, notes.Files[0].Name())
+		assert.Equal(t, test.LocationExtraFile, notes.Files[1].Name())
+	})
+
 	t.Run("default filename", func(t *testing.T) {
 		// Arrange
 		tempHome := t.TempDir()
@@ -102,3 +117,266 @@ func TestNewNotesFiles(t *testing.T) {
 		assert.Equal(t, filepath.Join(home, fcqs.DefaultNotesFile), notes.Files[0].Name())
 	})
 }
+
+// writeNotesTree writes empty files of the names relative to the directory.
+func writeNotesTree(t *testing.T, dir string, names ...string) {
+	t.Helper()
+
+	for _, name := range names {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte("# "+name+"\ncontents\n"), 0o600))
+	}
+}
+
+func TestOpenNotesFilesInDirectories(t *testing.T) {
+	dir := t.TempDir()
+	writeNotesTree(t, dir,
+		"b.md", "a.markdown", "memo.txt",
+		"sub/c.md", "sub/deep/d.md",
+		".git/e.md", "node_modules/pkg/f.md",
+	)
+	join := func(names ...string) []string {
+		var paths []string
+		for _, name := range names {
+			paths = append(paths, filepath.Join(dir, filepath.FromSlash(name)))
+		}
+		return paths
+	}
+
+	tests := []struct {
+		name   string
+		files  []string
+		ignore string
+		expect []string
+	}{
+		{
+			name:   "directory",
+			files:  []string{dir},
+			expect: join("a.markdown", "b.md", "sub/c.md", "sub/deep/d.md"),
+		},
+		{
+			name:   "glob pattern",
+			files:  []string{filepath.Join(dir, "*.md")},
+			expect: join("b.md"),
+		},
+		{
+			name:   "globstar",
+			files:  []string{filepath.Join(dir, "**", "*.md")},
+			expect: join("b.md", "sub/c.md", "sub/deep/d.md"),
+		},
+		{
+			name:   "glob pattern matching directories",
+			files:  []string{filepath.Join(dir, "s*")},
+			expect: join("sub/c.md", "sub/deep/d.md"),
+		},
+		{
+			name:   "duplicated files",
+			files:  []string{filepath.Join(dir, "sub", "c.md"), filepath.Join(dir, "sub")},
+			expect: join("sub/c.md", "sub/deep/d.md"),
+		},
+		{
+			name:   "ignore patterns",
+			files:  []string{dir},
+			ignore: test.MultiFiles("deep", "*.markdown"),
+			expect: join(".git/e.md", "b.md", "node_modules/pkg/f.md", "sub/c.md"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("FCQS_NOTES_FILE", "")
+			t.Setenv("FCQS_NOTES_FILES", test.MultiFiles(tc.files...))
+			t.Setenv("FCQS_NOTES_IGNORE", tc.ignore)
+
+			notes, err := fcqs.OpenNotesFiles()
+			require.NoError(t, err)
+			defer notes.Close()
+
+			fileNames := []string{}
+			for _, f := range notes.Files {
+				fileNames = append(fileNames, f.Name())
+			}
+			assert.Equal(t, tc.expect, fileNames)
+		})
+	}
+}
+
+func TestOpenNotesFilesWithoutMatches(t *testing.T) {
+	dir := t.TempDir()
+	writeNotesTree(t, dir, "memo.txt", "sub/a.md")
+
+	tests := []struct {
+		name   string
+		file   string
+		ignore string
+	}{
+		{name: "glob pattern under a missing directory", file: filepath.Join(dir, "missing", "*.md")},
+		{name: "glob pattern", file: filepath.Join(dir, "*.md")},
+		{name: "directory without notes files", file: filepath.Join(dir, "sub"), ignore: "a.md"},
+		{name: "globstar with ignored files", file: filepath.Join(dir, "**", "*.md"), ignore: "sub"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("FCQS_NOTES_FILE", "")
+			t.Setenv("FCQS_NOTES_FILES", test.MultiFiles(filepath.Join(dir, "sub", "a.md"), tc.file))
+			t.Setenv("FCQS_NOTES_IGNORE", tc.ignore)
+
+			notes, err := fcqs.OpenNotesFiles()
+
+			require.ErrorIs(t, err, fs.ErrNotExist)
+			require.EqualError(t, err, "notes file name: file does not exist: "+tc.file)
+			assert.Nil(t, notes)
+		})
+	}
+}
+
+// chdir changes the working directory during the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) }) //nolint:errcheck
+}
+
+func TestOpenProjectNotesFiles(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	writeNotesTree(t, dir,
+		"repo/fcnotes.md", "repo/.fcqs/b.md", "repo/.fcqs/a.md", "repo/.fcqs/memo.txt",
+		"repo/sub/fcnotes.md", "repo/sub/work/main.go",
+		"other/fcnotes.md", "other/work/fcnotes.md",
+		"home/fcnotes.md",
+	)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "repo", ".git"), 0o700))
+	join := func(names ...string) []string {
+		var paths []string
+		for _, name := range names {
+			paths = append(paths, filepath.Join(dir, filepath.FromSlash(name)))
+		}
+		return paths
+	}
+
+	tests := []struct {
+		name    string
+		wd      string
+		files   []string
+		project bool
+		expect  []string
+		sources []string
+	}{
+		{
+			name:    "repository",
+			wd:      "repo/sub/work",
+			files:   join("home/fcnotes.md"),
+			project: true,
+			expect:  join("repo/sub/fcnotes.md", "repo/fcnotes.md", "repo/.fcqs/a.md", "repo/.fcqs/b.md", "home/fcnotes.md"),
+			sources: []string{fcqs.SourceProject, fcqs.SourceProject, fcqs.SourceProject, fcqs.SourceProject, fcqs.SourceGlobal},
+		},
+		{
+			name:    "global notes file in the project",
+			wd:      "repo",
+			files:   join("repo/fcnotes.md", "home/fcnotes.md"),
+			project: true,
+			expect:  join("repo/fcnotes.md", "repo/.fcqs/a.md", "repo/.fcqs/b.md", "home/fcnotes.md"),
+			sources: []string{fcqs.SourceProject, fcqs.SourceProject, fcqs.SourceProject, fcqs.SourceGlobal},
+		},
+		{
+			name:    "outside repositories",
+			wd:      "other/work",
+			files:   join("home/fcnotes.md"),
+			project: true,
+			expect:  join("other/work/fcnotes.md", "home/fcnotes.md"),
+			sources: []string{fcqs.SourceProject, fcqs.SourceGlobal},
+		},
+		{
+			name:    "home directory",
+			wd:      "home",
+			files:   join("home/fcnotes.md"),
+			project: true,
+			expect:  join("home/fcnotes.md"),
+			sources: []string{fcqs.SourceGlobal},
+		},
+		{
+			name:    "disabled",
+			wd:      "repo/sub/work",
+			files:   join("home/fcnotes.md"),
+			project: false,
+			expect:  join("home/fcnotes.md"),
+			sources: []string{fcqs.SourceGlobal},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("HOME", filepath.Join(dir, "home"))
+			t.Setenv("FCQS_NOTES_FILE", "")
+			t.Setenv("FCQS_NOTES_FILES", "")
+			chdir(t, filepath.Join(dir, filepath.FromSlash(tc.wd)))
+
+			notes, err := fcqs.OpenNotesFilesWithConfig(fcqs.NotesConfig{Files: tc.files, Project: tc.project})
+			require.NoError(t, err)
+			defer notes.Close()
+
+			fileNames := []string{}
+			for _, f := range notes.Files {
+				fileNames = append(fileNames, f.Name())
+			}
+			assert.Equal(t, tc.expect, fileNames)
+			assert.Equal(t, tc.sources, notes.Sources)
+		})
+	}
+}
+
+func TestNotesFileToAdd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("FCQS_NOTES_FILE", "")
+
+	tests := []struct {
+		name     string
+		files    string
+		expected string
+		err      error
+	}{
+		{name: "first file", files: test.MultiFiles(filepath.Join(dir, "new.md"), dir), expected: filepath.Join(dir, "new.md")},
+		{name: "directory", files: test.MultiFiles(dir, filepath.Join(dir, "new.md")), err: fcqs.ErrNotNotesFile},
+		{name: "glob pattern", files: filepath.Join(dir, "*.md"), err: fcqs.ErrNotNotesFile},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("FCQS_NOTES_FILES", tc.files)
+
+			file, err := fcqs.NotesFileToAdd(fcqs.NotesConfig{})
+
+			require.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.expected, file)
+		})
+	}
+}
+
+func TestOpenExistingNotesFiles(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "new.md")
+	t.Setenv("FCQS_NOTES_FILE", "")
+	t.Setenv("FCQS_NOTES_FILES", test.MultiFiles(missing, test.NotesFile))
+
+	t.Run("missing file is skipped", func(t *testing.T) {
+		notes, err := fcqs.OpenExistingNotesFiles(fcqs.NotesConfig{})
+
+		require.NoError(t, err)
+		t.Cleanup(notes.Close)
+		require.Len(t, notes.Files, 1)
+		assert.Equal(t, test.NotesFile, notes.Files[0].Name())
+		assert.Equal(t, []string{fcqs.SourceGlobal}, notes.Sources)
+	})
+
+	t.Run("missing file is an error without skipping", func(t *testing.T) {
+		notes, err := fcqs.OpenNotesFiles()
+
+		require.ErrorIs(t, err, fs.ErrNotExist)
+		assert.Nil(t, notes)
+	})
+}
@@ -3,13 +3,16 @@ module github.com/yendo/fcqs
 go 1.23.4
 
 require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/term v0.27.0
 	mvdan.cc/xurls/v2 v2.6.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,111 @@
+package fcqs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+)
+
+var ErrEmptyPattern = errors.New("empty pattern")
+
+// NewGrepPattern returns the pattern to search the notes for.
+// The pattern is a regular expression if isRegexp is true, otherwise a literal string.
+func NewGrepPattern(pattern string, isRegexp bool) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, ErrEmptyPattern
+	}
+
+	if !isRegexp {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+
+	rx, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("grep pattern: %w", err)
+	}
+
+	return rx, nil
+}
+
+// WriteGrepResults writes the lines matching the pattern in the bodies of the notes.
+// Each line is written as the title, the line number and the line separated by tabs.
+func WriteGrepResults(w io.Writer, files []*os.File, pattern *regexp.Regexp) error {
+	return writeGrepResults(w, files, pattern, false)
+}
+
+// WriteHierarchicalGrepResults writes the lines matching the pattern in the bodies of the notes
+// with the title paths instead of the titles.
+func WriteHierarchicalGrepResults(w io.Writer, files []*os.File, pattern *regexp.Regexp) error {
+	return writeGrepResults(w, files, pattern, true)
+}
+
+// writeGrepResults writes the matching lines with the titles or the title paths of the notes.
+func writeGrepResults(w io.Writer, files []*os.File, pattern *regexp.Regexp, hierarchical bool) error {
+	results, err := GrepNotes(files, pattern, hierarchical)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", result.Title, result.Line, result.Text)
+	}
+
+	return nil
+}
+
+// GrepResult represents a line matching the pattern in the body of a note.
+type GrepResult struct {
+	Title string `json:"title"`
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+	Text  string `json:"text"`
+}
+
+// GrepNotes returns the lines matching the pattern in the bodies of the notes.
+// The titles are the title paths if hierarchical is true.
+// Lines outside notes and title lines are not searched.
+func GrepNotes(files []*os.File, pattern *regexp.Regexp, hierarchical bool) ([]GrepResult, error) {
+	var results []GrepResult
+
+	for _, file := range files {
+		nb, err := parseNotebook(newScanner(file), file.Name())
+		if err != nil {
+			return nil, fmt.Errorf("grep notes: %w", err)
+		}
+
+		for note := range nb.All() {
+			title, ok := note.stack.title(hierarchical)
+			if !ok {
+				continue
+			}
+
+			for i, line := range note.lines {
+				if pattern.MatchString(line) {
+					results = append(results, GrepResult{Title: title, File: note.File, Line: note.bodyLine(i), Text: line})
+				}
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// title returns the title path of the current note if hierarchical is true, otherwise its title.
+// It reports false if the line is outside notes.
+func (hs headingStack) title(hierarchical bool) (string, bool) {
+	if hierarchical {
+		path, ok := hs.path()
+		if !ok {
+			return "", false
+		}
+		return path.String(), true
+	}
+
+	if len(hs) == 0 || hs[len(hs)-1].title == nil {
+		return "", false
+	}
+
+	return hs[len(hs)-1].title.String(), true
+}
@@ -0,0 +1,170 @@
+package fcqs_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yendo/fcqs"
+	"github.com/yendo/fcqs/test"
+)
+
+func TestNewGrepPattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		pattern  string
+		isRegexp bool
+		line     string
+		expect   bool
+	}{
+		{name: "literal", pattern: "a.c", line: "xa.cx", expect: true},
+		{name: "literal not regexp", pattern: "a.c", line: "abc", expect: false},
+		{name: "literal case sensitive", pattern: "abc", line: "ABC", expect: false},
+		{name: "regexp", pattern: "a.c", isRegexp: true, line: "abc", expect: true},
+		{name: "regexp case insensitive", pattern: "(?i)abc", isRegexp: true, line: "ABC", expect: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rx, err := fcqs.NewGrepPattern(tc.pattern, tc.isRegexp)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, rx.MatchString(tc.line))
+		})
+	}
+
+	t.Run("empty pattern", func(t *testing.T) {
+		t.Parallel()
+
+		rx, err := fcqs.NewGrepPattern("", false)
+
+		require.ErrorIs(t, err, fcqs.ErrEmptyPattern)
+		assert.Nil(t, rx)
+	})
+
+	t.Run("invalid regexp", func(t *testing.T) {
+		t.Parallel()
+
+		rx, err := fcqs.NewGrepPattern("(", true)
+
+		require.EqualError(t, err, "grep pattern: error parsing regexp: missing closing ): `(`")
+		assert.Nil(t, rx)
+	})
+}
+
+func TestWriteGrepResults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		pattern  string
+		isRegexp bool
+		expect   string
+	}{
+		{
+			name:    "literal",
+			pattern: "iptables",
+			expect: "firewall\t5\tBlock a port with iptables.\n" +
+				"firewall\t8\tsudo iptables -A INPUT -p tcp --dport 22 -j DROP\n" +
+				"fenced heading\t14\t# iptables in a fence\n" +
+				"sub note\t23\tiptables-save > rules\n",
+		},
+		{
+			name:     "regexp",
+			pattern:  "(?i)^iptables",
+			isRegexp: true,
+			expect:   "other note\t19\tIPTABLES in upper case.\n" + "sub note\t23\tiptables-save > rules\n",
+		},
+		{
+			name:    "fence line",
+			pattern: "```sh",
+			expect:  "firewall\t7\t```sh\n",
+		},
+		{
+			name:    "title not searched",
+			pattern: "other note",
+			expect:  "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			file := openTestNotesFile(t, test.GrepFile)
+			rx, err := fcqs.NewGrepPattern(tc.pattern, tc.isRegexp)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = fcqs.WriteGrepResults(&buf, []*os.File{file}, rx)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, buf.String())
+		})
+	}
+
+	t.Run("multiple files", func(t *testing.T) {
+		t.Parallel()
+
+		files := []*os.File{openTestNotesFile(t, test.GrepFile), openTestNotesFile(t, test.HierarchyFile)}
+		rx, err := fcqs.NewGrepPattern("ls -l", false)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = fcqs.WriteGrepResults(&buf, files, rx)
+
+		require.NoError(t, err)
+		assert.Equal(t, "child\t10\tls -l | nl\n", buf.String())
+	})
+}
+
+func TestWriteHierarchicalGrepResults(t *testing.T) {
+	t.Parallel()
+
+	file := openTestNotesFile(t, test.GrepFile)
+	rx, err := fcqs.NewGrepPattern("(?i)^iptables", true)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = fcqs.WriteHierarchicalGrepResults(&buf, []*os.File{file}, rx)
+
+	require.NoError(t, err)
+	assert.Equal(t, "other note\t19\tIPTABLES in upper case.\n"+"other note/sub note\t23\tiptables-save > rules\n", buf.String())
+}
+
+func TestGrepNotes(t *testing.T) {
+	t.Parallel()
+
+	file := openTestNotesFile(t, test.GrepFile)
+	rx, err := fcqs.NewGrepPattern("iptables-save", false)
+	require.NoError(t, err)
+
+	results, err := fcqs.GrepNotes([]*os.File{file}, rx, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, []fcqs.GrepResult{
+		{Title: "other note/sub note", File: file.Name(), Line: 23, Text: "iptables-save > rules"},
+	}, results)
+}
+
+func TestWriteGrepResultsFail(t *testing.T) {
+	// Cannot run tests in parallel due to fcqs.SetNewScannerMock
+	fcqs.SetNewScannerMock(t, ErrScanForTest)
+
+	file := openTestNotesFile(t, test.GrepFile)
+	rx, err := fcqs.NewGrepPattern("iptables", false)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = fcqs.WriteGrepResults(&buf, []*os.File{file}, rx)
+
+	require.EqualError(t, err, fmt.Sprintf("grep notes: %s", ErrScanForTest))
+	assert.Empty(t, buf.String())
+}
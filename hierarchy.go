@@ -0,0 +1,169 @@
+package fcqs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/yendo/fcqs/internal/value"
+)
+
+const titleIndent = "  "
+
+// heading represents a heading in the hierarchy of notes.
+type heading struct {
+	level int
+	title *value.Title
+}
+
+// headingStack represents headings from a top-level note to the current note.
+type headingStack []heading
+
+// push returns the heading stack with the title line after removing headings of the same or deeper level.
+func (hs headingStack) push(tl *value.TitleLine) headingStack {
+	for len(hs) > 0 && hs[len(hs)-1].level >= tl.Level() {
+		hs = hs[:len(hs)-1]
+	}
+
+	var title *value.Title
+	if tl.HasValidTitle() {
+		t := tl.Title()
+		title = &t
+	}
+
+	return append(hs, heading{level: tl.Level(), title: title})
+}
+
+// path returns the title path of the current note.
+// It reports false if the path has an empty title.
+func (hs headingStack) path() (*value.TitlePath, bool) {
+	titles := make([]value.Title, 0, len(hs))
+	for _, h := range hs {
+		if h.title == nil {
+			return nil, false
+		}
+		titles = append(titles, *h.title)
+	}
+
+	path, err := value.NewTitlePathFromTitles(titles)
+	if err != nil {
+		return nil, false
+	}
+
+	return path, true
+}
+
+// matches reports whether the title path of the current note equals to the path.
+func (hs headingStack) matches(path *value.TitlePath) bool {
+	p, ok := hs.path()
+
+	return ok && p.Equals(path)
+}
+
+// WriteHierarchicalTitles writes the title paths of all notes including sub notes.
+// The titles are written as indented titles if indent is true, otherwise as title paths.
+func WriteHierarchicalTitles(w io.Writer, r io.Reader, indent bool) error {
+	nb, err := parseNotebook(bufio.NewScanner(r), "")
+	if err != nil {
+		return fmt.Errorf("seek titles: %w", err)
+	}
+
+	allPaths := make(map[string]bool)
+	for note := range nb.All() {
+		if !note.hasContents() {
+			continue
+		}
+
+		// Parent notes are written before the sub notes.
+		for i := range note.stack {
+			path, ok := note.stack[:i+1].path()
+			if !ok {
+				break
+			}
+			if allPaths[path.String()] {
+				continue
+			}
+
+			if indent {
+				fmt.Fprintln(w, strings.Repeat(titleIndent, i)+path.Leaf().String())
+			} else {
+				fmt.Fprintln(w, path)
+			}
+			allPaths[path.String()] = true
+		}
+	}
+
+	return nil
+}
+
+// WriteHierarchicalContents writes the contents of the note including sub notes.
+func WriteHierarchicalContents(w io.Writer, r io.Reader, path *value.TitlePath, isNoTitle bool) error {
+	nb, err := parseNotebook(bufio.NewScanner(r), "")
+	if err != nil {
+		return fmt.Errorf("seek contents: %w", err)
+	}
+
+	f := newFilter(w, isNoTitle)
+	defer f.Close()
+
+	for note := range nb.findTree(path) {
+		note.writeTo(f)
+	}
+
+	return nil
+}
+
+// WriteHierarchicalFirstURL writes the first URL in the contents of the note including sub notes.
+func WriteHierarchicalFirstURL(w io.Writer, r io.Reader, path *value.TitlePath) error {
+	var buf bytes.Buffer
+	if err := WriteHierarchicalContents(&buf, r, path, false); err != nil {
+		return err
+	}
+	writeFirstURL(w, buf.String())
+
+	return nil
+}
+
+// WriteHierarchicalFirstCmdLineBlock writes the first command-line block for the shell in the contents of the note including sub notes.
+func WriteHierarchicalFirstCmdLineBlock(w io.Writer, r io.Reader, path *value.TitlePath, shell string) error {
+	var buf bytes.Buffer
+	if err := WriteHierarchicalContents(&buf, r, path, false); err != nil {
+		return err
+	}
+
+	return writeFirstCmdLineBlock(w, &buf, shell)
+}
+
+// WriteHierarchicalNoteLocation writes the file name and line number of the note specified by the title path.
+func WriteHierarchicalNoteLocation(w io.Writer, files []*os.File, path *value.TitlePath) error {
+	locations, err := HierarchicalNoteLocations(files, path)
+	if err != nil {
+		return err
+	}
+
+	writeLocations(w, locations)
+
+	return nil
+}
+
+// HierarchicalNoteLocations returns the first location of the note with the title path in each file.
+func HierarchicalNoteLocations(files []*os.File, path *value.TitlePath) ([]Location, error) {
+	var locations []Location
+
+	for _, file := range files {
+		nb, err := parseNotebook(newScanner(file), file.Name())
+		if err != nil {
+			return nil, fmt.Errorf("seek note location: %w", err)
+		}
+
+		for note := range nb.findPath(path) {
+			locations = append(locations, Location{File: note.File, Line: note.Line})
+			break
+		}
+	}
+
+	return locations, nil
+}
@@ -0,0 +1,144 @@
+package fcqs_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yendo/fcqs"
+	"github.com/yendo/fcqs/internal/value"
+	"github.com/yendo/fcqs/test"
+)
+
+func TestWriteHierarchicalTitles(t *testing.T) {
+	t.Parallel()
+
+	t.Run("title paths", func(t *testing.T) {
+		t.Parallel()
+
+		file := openTestNotesFile(t, test.HierarchyFile)
+
+		var buf bytes.Buffer
+		err := fcqs.WriteHierarchicalTitles(&buf, file, false)
+
+		require.NoError(t, err)
+		assert.Equal(t, "parent\nparent/child\nparent/child/grandchild\nparent/other child\n"+
+			"TCP\\/IP\nTCP\\/IP/child\nsections only\nsections only/section\n", buf.String())
+	})
+
+	t.Run("indented titles", func(t *testing.T) {
+		t.Parallel()
+
+		file := openTestNotesFile(t, test.HierarchyFile)
+
+		var buf bytes.Buffer
+		err := fcqs.WriteHierarchicalTitles(&buf, file, true)
+
+		require.NoError(t, err)
+		assert.Equal(t, "parent\n  child\n    grandchild\n  other child\n"+
+			"TCP/IP\n  child\nsections only\n  section\n", buf.String())
+	})
+
+	t.Run("fail with scan error", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		err := fcqs.WriteHierarchicalTitles(&buf, iotest.ErrReader(ErrScanForTest), false)
+
+		require.EqualError(t, err, fmt.Sprintf("seek titles: %s", ErrScanForTest))
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestWriteHierarchicalContents(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path     string
+		contents string
+	}{
+		{"parent", "# parent\n\nparent contents\n\n## child\n\nchild contents\n\n```sh\nls -l | nl\n```\n\n" +
+			"### grandchild\n\ngrandchild: https://github.com/yendo/fcqs/\n\n## other child\n\nother child contents\n"},
+		{"parent/child", "## child\n\nchild contents\n\n```sh\nls -l | nl\n```\n\n" +
+			"### grandchild\n\ngrandchild: https://github.com/yendo/fcqs/\n"},
+		{"parent/child/grandchild", "### grandchild\n\ngrandchild: https://github.com/yendo/fcqs/\n"},
+		{"TCP\\/IP/child", "## child\n\nTCP/IP child contents\n"},
+		{"sections only", "sections only\n=============\n\nsection\n-------\n\nsection contents\n"},
+		{"child", ""},
+		{"parent/grandchild", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			t.Parallel()
+
+			file := openTestNotesFile(t, test.HierarchyFile)
+			path, err := value.NewTitlePath(tc.path)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = fcqs.WriteHierarchicalContents(&buf, file, path, false)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.contents, buf.String())
+		})
+	}
+
+	t.Run("scan failed", func(t *testing.T) {
+		t.Parallel()
+
+		path, err := value.NewTitlePath("parent")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = fcqs.WriteHierarchicalContents(&buf, iotest.ErrReader(ErrScanForTest), path, false)
+
+		require.EqualError(t, err, fmt.Sprintf("seek contents: %s", ErrScanForTest))
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestWriteHierarchicalFirstURLAndCmdLineBlock(t *testing.T) {
+	t.Parallel()
+
+	path, err := value.NewTitlePath("parent")
+	require.NoError(t, err)
+
+	t.Run("first URL", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		err := fcqs.WriteHierarchicalFirstURL(&buf, openTestNotesFile(t, test.HierarchyFile), path)
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.com/yendo/fcqs/\n", buf.String())
+	})
+
+	t.Run("first command-line block", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		err := fcqs.WriteHierarchicalFirstCmdLineBlock(&buf, openTestNotesFile(t, test.HierarchyFile), path, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, "ls -l | nl\n", buf.String())
+	})
+}
+
+func TestWriteHierarchicalNoteLocation(t *testing.T) {
+	t.Parallel()
+
+	testFile := openTestNotesFile(t, test.HierarchyFile)
+	path, err := value.NewTitlePath("parent/other child")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = fcqs.WriteHierarchicalNoteLocation(&buf, []*os.File{testFile}, path)
+
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%q 17\n", testFile.Name()), buf.String())
+}
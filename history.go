@@ -0,0 +1,128 @@
+package fcqs
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	historyDir  = "fcqs"
+	historyFile = "history"
+
+	// Score of visits older than the frecency buckets.
+	frecencyOldScore = 10
+)
+
+// frecencyBuckets are scores of a visit by its age, as Firefox does.
+var frecencyBuckets = []struct {
+	age   time.Duration
+	score float64
+}{
+	{age: 4 * 24 * time.Hour, score: 100},
+	{age: 14 * 24 * time.Hour, score: 70},
+	{age: 31 * 24 * time.Hour, score: 50},
+	{age: 90 * 24 * time.Hour, score: 30},
+}
+
+// HistoryFile returns the path of the history file of selected titles.
+// The file is in $XDG_STATE_HOME, which defaults to ~/.local/state.
+func HistoryFile() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if !filepath.IsAbs(dir) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("user home directory: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+
+	return filepath.Join(dir, historyDir, historyFile), nil
+}
+
+// RecordHistory appends the title selected at the time to the history file.
+func RecordHistory(file string, title string, at time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return fmt.Errorf("history directory: %w", err)
+	}
+
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("history file: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(f, "%d\t%s\n", at.Unix(), title); err != nil {
+		f.Close()
+		return fmt.Errorf("record history: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("record history: %w", err)
+	}
+
+	return nil
+}
+
+// Frecency represents scores of titles by frequency and recency of use.
+type Frecency map[string]float64
+
+// ReadFrecency returns the frecency of titles in the history file at the time.
+// It returns empty frecency if the history file does not exist.
+func ReadFrecency(file string, now time.Time) (Frecency, error) {
+	frecency := make(Frecency)
+
+	f, err := os.Open(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return frecency, nil
+		}
+		return nil, fmt.Errorf("history file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Broken lines are ignored.
+		unix, title, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		sec, err := strconv.ParseInt(unix, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		frecency[title] += frecencyScore(now.Sub(time.Unix(sec, 0)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	return frecency, nil
+}
+
+// frecencyScore returns the score of a visit of the age.
+func frecencyScore(age time.Duration) float64 {
+	for _, bucket := range frecencyBuckets {
+		if age < bucket.age {
+			return bucket.score
+		}
+	}
+
+	return frecencyOldScore
+}
+
+// Sort sorts the titles by the frecency in descending order.
+// Titles of the same frecency keep the original order.
+func (f Frecency) Sort(titles []string) {
+	slices.SortStableFunc(titles, func(a, b string) int {
+		return cmp.Compare(f[b], f[a])
+	})
+}
@@ -0,0 +1,123 @@
+package fcqs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yendo/fcqs"
+)
+
+func TestHistoryFile(t *testing.T) {
+	t.Run("XDG_STATE_HOME", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "/tmp/state")
+
+		file, err := fcqs.HistoryFile()
+
+		require.NoError(t, err)
+		assert.Equal(t, "/tmp/state/fcqs/history", file)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "relative")
+		t.Setenv("HOME", "/home/user")
+
+		file, err := fcqs.HistoryFile()
+
+		require.NoError(t, err)
+		assert.Equal(t, "/home/user/.local/state/fcqs/history", file)
+	})
+
+	t.Run("no home directory", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "")
+		t.Setenv("HOME", "")
+
+		file, err := fcqs.HistoryFile()
+
+		require.EqualError(t, err, "user home directory: $HOME is not defined")
+		assert.Empty(t, file)
+	})
+}
+
+func TestFrecency(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	t.Run("sort by frecency", func(t *testing.T) {
+		t.Parallel()
+
+		file := filepath.Join(t.TempDir(), "fcqs", "history")
+		for _, h := range []struct {
+			title string
+			age   time.Duration
+		}{
+			{"old but frequent", 100 * day},
+			{"old but frequent", 100 * day},
+			{"old but frequent", 100 * day},
+			{"recent", 1 * day},
+			{"two weeks ago", 10 * day},
+			{"two weeks ago", 10 * day},
+			{"title\twith tab", 20 * day},
+		} {
+			err := fcqs.RecordHistory(file, h.title, now.Add(-h.age))
+			require.NoError(t, err)
+		}
+
+		frecency, err := fcqs.ReadFrecency(file, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, fcqs.Frecency{"old but frequent": 30, "recent": 100, "two weeks ago": 140, "title\twith tab": 50}, frecency)
+
+		titles := []string{"never", "old but frequent", "title\twith tab", "recent", "other", "two weeks ago"}
+		frecency.Sort(titles)
+
+		assert.Equal(t, []string{"two weeks ago", "recent", "title\twith tab", "old but frequent", "never", "other"}, titles)
+	})
+
+	t.Run("no history file", func(t *testing.T) {
+		t.Parallel()
+
+		frecency, err := fcqs.ReadFrecency(filepath.Join(t.TempDir(), "no_exist"), now)
+
+		require.NoError(t, err)
+		assert.Empty(t, frecency)
+	})
+
+	t.Run("broken lines", func(t *testing.T) {
+		t.Parallel()
+
+		file := filepath.Join(t.TempDir(), "history")
+		err := os.WriteFile(file, []byte("broken\nxxx\ttitle\n1738281600\ttitle\n"), 0o600)
+		require.NoError(t, err)
+
+		frecency, err := fcqs.ReadFrecency(file, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, fcqs.Frecency{"title": 100}, frecency)
+	})
+
+	t.Run("fail to read history file", func(t *testing.T) {
+		t.Parallel()
+
+		frecency, err := fcqs.ReadFrecency(t.TempDir(), now)
+
+		require.ErrorContains(t, err, "read history: ")
+		assert.Nil(t, frecency)
+	})
+
+	t.Run("fail to record history", func(t *testing.T) {
+		t.Parallel()
+
+		file := filepath.Join(t.TempDir(), "history")
+		require.NoError(t, os.Mkdir(file, 0o700))
+
+		err := fcqs.RecordHistory(file, "title", now)
+
+		require.ErrorContains(t, err, "history file: ")
+	})
+}
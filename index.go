@@ -0,0 +1,401 @@
+package fcqs
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yendo/fcqs/internal/value"
+)
+
+const (
+	indexCacheDir  = "fcqs"
+	indexCacheFile = "index.gob"
+
+	// indexVersion is incremented when the format or the parsing of the index cache is changed.
+	indexVersion = 3
+)
+
+// section represents a byte range of a part of a note in a notes file.
+type section struct {
+	Offset int64
+	Length int64
+}
+
+// noteIndex represents an indexed note in a notes file.
+type noteIndex struct {
+	Line     int
+	Sections []section
+	FirstURL string
+	FirstCmd string
+}
+
+// reader returns a reader of the contents of the note.
+func (n noteIndex) reader(r io.ReaderAt) io.Reader {
+	readers := make([]io.Reader, 0, len(n.Sections))
+	for _, s := range n.Sections {
+		readers = append(readers, io.NewSectionReader(r, s.Offset, s.Length))
+	}
+
+	return io.MultiReader(readers...)
+}
+
+// fileIndex represents an indexed notes file.
+type fileIndex struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Hash    string
+	Titles  []string
+	Notes   map[string]*noteIndex
+}
+
+// indexCache represents the index cache file.
+type indexCache struct {
+	Version int
+	// Shells are the shell languages of fences, which change the first command-line blocks.
+	Shells map[string]string
+	Files  []*fileIndex
+}
+
+// Index represents a parsed index of notes files.
+type Index struct {
+	files   []*os.File
+	entries []*fileIndex
+}
+
+// WriteTitles writes the titles of all notes.
+func (idx *Index) WriteTitles(w io.Writer) error {
+	allTitles := make(map[string]bool)
+
+	for _, entry := range idx.entries {
+		for _, title := range entry.Titles {
+			if !allTitles[title] {
+				fmt.Fprintln(w, title)
+				allTitles[title] = true
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteContents writes the contents of the note.
+func (idx *Index) WriteContents(w io.Writer, title *value.Title, isNoTitle bool) error {
+	var readers []io.Reader
+
+	for i, entry := range idx.entries {
+		if note, ok := entry.Notes[title.String()]; ok {
+			readers = append(readers, note.reader(idx.files[i]))
+		}
+	}
+
+	return WriteContents(w, io.MultiReader(readers...), title, isNoTitle)
+}
+
+// WriteFirstURL writes the first URL in the contents of the note.
+func (idx *Index) WriteFirstURL(w io.Writer, title *value.Title) error {
+	for _, entry := range idx.entries {
+		if note, ok := entry.Notes[title.String()]; ok && note.FirstURL != "" {
+			fmt.Fprintln(w, note.FirstURL)
+			break
+		}
+	}
+
+	return nil
+}
+
+// WriteFirstCmdLineBlock writes the first command-line block in the contents of the note.
+func (idx *Index) WriteFirstCmdLineBlock(w io.Writer, title *value.Title) error {
+	for _, entry := range idx.entries {
+		if note, ok := entry.Notes[title.String()]; ok && note.FirstCmd != "" {
+			fmt.Fprint(w, note.FirstCmd)
+			break
+		}
+	}
+
+	return nil
+}
+
+// WriteNoteLocation writes the file name and line number of the note.
+func (idx *Index) WriteNoteLocation(w io.Writer, title *value.Title) error {
+	writeLocations(w, idx.NoteLocations(title))
+
+	return nil
+}
+
+// NoteLocations returns the location of the note in each file.
+func (idx *Index) NoteLocations(title *value.Title) []Location {
+	var locations []Location
+	for i, entry := range idx.entries {
+		if note, ok := entry.Notes[title.String()]; ok {
+			locations = append(locations, Location{File: idx.files[i].Name(), Line: note.Line})
+		}
+	}
+
+	return locations
+}
+
+// IndexCacheFile returns the path of the index cache file.
+// It returns an empty string if the user cache directory is not available.
+func IndexCacheFile() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, indexCacheDir, indexCacheFile)
+}
+
+// OpenIndex returns the index of the notes files.
+// Indexes of unchanged files are loaded from the cache file, and the others are parsed and saved.
+// The cache file keeps the indexes of other files as well so that switching sets of notes files
+// does not parse them again, and the indexes of removed files are dropped.
+// The cache file is not used if cacheFile is empty.
+func OpenIndex(files []*os.File, cacheFile string) (*Index, error) {
+	cached := make(map[string]*fileIndex)
+	for _, entry := range loadIndexCache(cacheFile) {
+		cached[entry.Path] = entry
+	}
+
+	idx := &Index{files: files}
+	isUpdated := false
+
+	for _, file := range files {
+		entry, updated, err := indexFile(file, cached)
+		if err != nil {
+			return nil, fmt.Errorf("index notes file: %w", err)
+		}
+		idx.entries = append(idx.entries, entry)
+		isUpdated = isUpdated || updated
+		cached[entry.Path] = entry
+	}
+
+	for path := range cached {
+		if _, err := os.Stat(path); err != nil {
+			delete(cached, path)
+			isUpdated = true
+		}
+	}
+
+	// The index is usable even if the cache cannot be saved.
+	if isUpdated {
+		entries := slices.SortedFunc(maps.Values(cached), func(a, b *fileIndex) int {
+			return strings.Compare(a.Path, b.Path)
+		})
+		saveIndexCache(cacheFile, entries) //nolint:errcheck
+	}
+
+	return idx, nil
+}
+
+// indexFile returns the index of the file and reports whether it is updated from the cache.
+func indexFile(file *os.File, cached map[string]*fileIndex) (*fileIndex, bool, error) {
+	path, err := filepath.Abs(file.Name())
+	if err != nil {
+		return nil, false, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+
+	entry, ok := cached[path]
+	if ok && entry.Size == info.Size() && entry.ModTime.Equal(info.ModTime()) {
+		return entry, false, nil
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(file, 0, info.Size()))
+	if err != nil {
+		return nil, false, err
+	}
+
+	hash := sha256.Sum256(data)
+	hashStr := hex.EncodeToString(hash[:])
+
+	// Touched but unchanged files are not parsed again.
+	if ok && entry.Hash == hashStr {
+		entry.Size, entry.ModTime = info.Size(), info.ModTime()
+		return entry, true, nil
+	}
+
+	entry, err = parseIndex(data)
+	if err != nil {
+		return nil, false, err
+	}
+	entry.Path, entry.Size, entry.ModTime, entry.Hash = path, info.Size(), info.ModTime(), hashStr
+
+	return entry, true, nil
+}
+
+// parseIndex returns the index of the notes file data.
+func parseIndex(data []byte) (*fileIndex, error) {
+	var buf bytes.Buffer
+	if err := WriteTitles(&buf, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	var titles []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		titles = append(titles, scanner.Text())
+	}
+
+	notes, err := indexNotes(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	r := bytes.NewReader(data)
+	for titleStr, note := range notes {
+		title, err := value.NewTitle(titleStr)
+		if err != nil {
+			return nil, err
+		}
+
+		var url, cmd bytes.Buffer
+		if err := WriteFirstURL(&url, note.reader(r), title); err != nil {
+			return nil, err
+		}
+		if err := WriteFirstCmdLineBlock(&cmd, note.reader(r), title, ""); err != nil {
+			return nil, err
+		}
+		note.FirstURL = string(bytes.TrimSuffix(url.Bytes(), []byte("\n")))
+		note.FirstCmd = cmd.String()
+	}
+
+	return &fileIndex{Titles: titles, Notes: notes}, nil
+}
+
+// indexNotes returns the line numbers and the sections of all notes in the notes file.
+// A section starts at the title line and ends before the next title line of other notes.
+func indexNotes(r io.Reader, size int64) (map[string]*noteIndex, error) {
+	notes := make(map[string]*noteIndex)
+
+	var current *noteIndex
+	var currentTitle *value.Title
+	var start int64
+	var fence *value.FenceLine
+
+	closeSection := func(end int64) {
+		if current != nil {
+			current.Sections = append(current.Sections, section{Offset: start, Length: end - start})
+		}
+		current, currentTitle = nil, nil
+	}
+
+	scanner := newLineScanner(bufio.NewScanner(r))
+	state := normal
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		offset, lineNum := scanner.Offset(), scanner.LineNumber()
+
+		switch state {
+		case normal:
+			if fl, ok := value.NewFenceLine(line); ok {
+				fence = fl
+				state = fenced
+				break
+			}
+
+			tl, _, ok := scanner.scanTitleLine()
+			if !ok || (currentTitle != nil && tl.EqualTitle(currentTitle)) {
+				break
+			}
+
+			closeSection(offset)
+			if !tl.HasValidTitle() {
+				break
+			}
+
+			title := tl.Title()
+			note, ok := notes[title.String()]
+			if !ok {
+				note = &noteIndex{Line: lineNum}
+				notes[title.String()] = note
+			}
+			current, currentTitle, start = note, &title, offset
+
+		case fenced:
+			if fence.IsClosedBy(line) {
+				state = normal
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("seek notes: %w", err)
+	}
+	closeSection(size)
+
+	return notes, nil
+}
+
+// loadIndexCache returns the indexes of files in the cache file.
+// A broken or old cache is ignored.
+func loadIndexCache(cacheFile string) []*fileIndex {
+	if cacheFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(cacheFile)
+	if err != nil {
+		return nil
+	}
+
+	var cache indexCache
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&cache); err != nil ||
+		cache.Version != indexVersion || !maps.Equal(cache.Shells, value.ShellLanguages()) {
+		return nil
+	}
+
+	return cache.Files
+}
+
+// saveIndexCache saves the indexes of files to the cache file atomically.
+func saveIndexCache(cacheFile string, entries []*fileIndex) error {
+	if cacheFile == "" {
+		return nil
+	}
+
+	var data bytes.Buffer
+	if err := gob.NewEncoder(&data).Encode(indexCache{Version: indexVersion, Shells: value.ShellLanguages(), Files: entries}); err != nil {
+		return fmt.Errorf("encode index cache: %w", err)
+	}
+
+	dir := filepath.Dir(cacheFile)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("index cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, indexCacheFile+".*")
+	if err != nil {
+		return fmt.Errorf("index cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write index cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write index cache: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), cacheFile); err != nil {
+		return fmt.Errorf("write index cache: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package fcqs_test
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yendo/fcqs"
+	"github.com/yendo/fcqs/internal/value"
+	"github.com/yendo/fcqs/test"
+)
+
+// testTitles returns all titles in the notes file.
+func testTitles(t *testing.T, filename string) []*value.Title {
+	t.Helper()
+
+	var buf bytes.Buffer
+	err := fcqs.WriteTitles(&buf, openTestNotesFile(t, filename))
+	require.NoError(t, err)
+
+	var titles []*value.Title
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		title, err := value.NewTitle(scanner.Text())
+		require.NoError(t, err)
+		titles = append(titles, title)
+	}
+
+	return titles
+}
+
+func TestIndexOutputsSameAsScan(t *testing.T) {
+	t.Parallel()
+
+	for _, filename := range []string{test.NotesFile, test.ShellBlockFile, test.HierarchyFile} {
+		t.Run(filepath.Base(filename), func(t *testing.T) {
+			t.Parallel()
+
+			file := openTestNotesFile(t, filename)
+			idx, err := fcqs.OpenIndex([]*os.File{file}, "")
+			require.NoError(t, err)
+
+			var expected, actual bytes.Buffer
+			require.NoError(t, fcqs.WriteTitles(&expected, openTestNotesFile(t, filename)))
+			require.NoError(t, idx.WriteTitles(&actual))
+			assert.Equal(t, expected.String(), actual.String())
+
+			for _, title := range testTitles(t, filename) {
+				for _, isNoTitle := range []bool{false, true} {
+					expected.Reset()
+					actual.Reset()
+					require.NoError(t, fcqs.WriteContents(&expected, openTestNotesFile(t, filename), title, isNoTitle))
+					require.NoError(t, idx.WriteContents(&actual, title, isNoTitle))
+					assert.Equal(t, expected.String(), actual.String(), title.String())
+				}
+
+				expected.Reset()
+				actual.Reset()
+				require.NoError(t, fcqs.WriteFirstURL(&expected, openTestNotesFile(t, filename), title))
+				require.NoError(t, idx.WriteFirstURL(&actual, title))
+				assert.Equal(t, expected.String(), actual.String(), title.String())
+
+				expected.Reset()
+				actual.Reset()
+				require.NoError(t, fcqs.WriteFirstCmdLineBlock(&expected, openTestNotesFile(t, filename), title, ""))
+				require.NoError(t, idx.WriteFirstCmdLineBlock(&actual, title))
+				assert.Equal(t, expected.String(), actual.String(), title.String())
+
+				expected.Reset()
+				actual.Reset()
+				require.NoError(t, fcqs.WriteNoteLocation(&expected, []*os.File{openTestNotesFile(t, filename)}, title))
+				require.NoError(t, idx.WriteNoteLocation(&actual, title))
+				assert.Equal(t, expected.String(), actual.String(), title.String())
+			}
+		})
+	}
+}
+
+func TestIndexMultiFiles(t *testing.T) {
+	t.Parallel()
+
+	file1 := openTestNotesFile(t, test.LocationFile)
+	file2 := openTestNotesFile(t, test.LocationExtraFile)
+	idx, err := fcqs.OpenIndex([]*os.File{file1, file2}, "")
+	require.NoError(t, err)
+
+	t.Run("titles", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		err := idx.WriteTitles(&buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, "location test data\n5th Line\nother 5th Line\n9th Line\n", buf.String())
+	})
+
+	t.Run("contents of the same title in files", func(t *testing.T) {
+		t.Parallel()
+
+		title, err := value.NewTitle("location test data")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = idx.WriteContents(&buf, title, false)
+
+		require.NoError(t, err)
+		assert.Equal(t, "# location test data\n\nThis file is location test data.\n\n"+
+			"# location test data\n\nThis file is location extra test data.\n", buf.String())
+	})
+
+	t.Run("location", func(t *testing.T) {
+		t.Parallel()
+
+		title, err := value.NewTitle("9th Line")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = idx.WriteNoteLocation(&buf, title)
+
+		require.NoError(t, err)
+		assert.Equal(t, "\""+file2.Name()+"\" 9\n", buf.String())
+	})
+}
+
+func TestIndexCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	notesFile := filepath.Join(dir, "notes.md")
+	cacheFile := filepath.Join(dir, "cache", "index.json")
+
+	writeTitles := func(t *testing.T) string {
+		t.Helper()
+
+		file := openTestNotesFile(t, notesFile)
+		idx, err := fcqs.OpenIndex([]*os.File{file}, cacheFile)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, idx.WriteTitles(&buf))
+
+		return buf.String()
+	}
+
+	require.NoError(t, os.WriteFile(notesFile, []byte("# title\n\ncontents\n"), 0o600))
+	assert.Equal(t, "title\n", writeTitles(t))
+	require.FileExists(t, cacheFile)
+
+	t.Run("changed file is indexed again", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(notesFile, []byte("# new title\n\ncontents\n"), 0o600))
+		assert.Equal(t, "new title\n", writeTitles(t))
+	})
+
+	t.Run("touched file uses cached index", func(t *testing.T) {
+		before, err := os.ReadFile(cacheFile)
+		require.NoError(t, err)
+
+		future := time.Now().Add(time.Hour)
+		require.NoError(t, os.Chtimes(notesFile, future, future))
+		assert.Equal(t, "new title\n", writeTitles(t))
+
+		// The cache is updated with the new modification time.
+		after, err := os.ReadFile(cacheFile)
+		require.NoError(t, err)
+		assert.NotEqual(t, before, after)
+	})
+
+	t.Run("broken cache is ignored", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(cacheFile, []byte("{broken"), 0o600))
+		assert.Equal(t, "new title\n", writeTitles(t))
+
+		data, err := os.ReadFile(cacheFile)
+		require.NoError(t, err)
+		assert.NotEqual(t, "{broken", string(data))
+	})
+}
+
+func TestIndexCacheWithFileSets(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.md"), filepath.Join(dir, "second.md")
+	cacheFile := filepath.Join(dir, "cache", "index.gob")
+	require.NoError(t, os.WriteFile(first, []byte("# first\n\ncontents\n"), 0o600))
+	require.NoError(t, os.WriteFile(second, []byte("# second\n\ncontents\n"), 0o600))
+
+	// openIndex opens the index of the file and returns the cache file data.
+	openIndex := func(t *testing.T, name string) []byte {
+		t.Helper()
+
+		_, err := fcqs.OpenIndex([]*os.File{openTestNotesFile(t, name)}, cacheFile)
+		require.NoError(t, err)
+		data, err := os.ReadFile(cacheFile)
+		require.NoError(t, err)
+
+		return data
+	}
+
+	openIndex(t, first)
+	both := openIndex(t, second)
+
+	// The index of the first file is kept in the cache, which is not saved again.
+	assert.Equal(t, both, openIndex(t, first))
+
+	// The index of the removed file is dropped.
+	require.NoError(t, os.Remove(second))
+	assert.NotEqual(t, both, openIndex(t, first))
+}
+
+func TestIndexCacheFile(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/tmp/cache")
+	assert.Equal(t, "/tmp/cache/fcqs/index.gob", fcqs.IndexCacheFile())
+
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("HOME", "")
+	assert.Empty(t, fcqs.IndexCacheFile())
+}
@@ -0,0 +1,35 @@
+package finder
+
+import (
+	"io"
+	"os"
+)
+
+var (
+	ExportDecodeEvents = decodeEvents
+	ExportTextWidth    = textWidth
+	ExportTruncate     = truncate
+)
+
+// ExportEvent returns the key and the character of the event.
+func ExportEvent(ev event) (Key, rune) {
+	return ev.key, ev.ch
+}
+
+// Loop runs the event loop with the input on the terminal of the size.
+// It returns the selected item and the executed commands.
+func (f *Finder) Loop(in io.Reader, out io.Writer, width, height int) (string, []string, error) {
+	var commands []string
+	f.execute = func(command string, _ *os.File) error {
+		commands = append(commands, command)
+		return nil
+	}
+
+	res, err := f.loop(newModel(f.items), in, out, func() (int, int, error) { return width, height, nil })
+	if res.action != nil {
+		commands = append(commands, f.command(*res.action, res.item))
+		return "", commands, err
+	}
+
+	return res.item, commands, err
+}
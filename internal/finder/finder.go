@@ -0,0 +1,262 @@
+// Package finder provides an interactive fuzzy finder on a terminal.
+package finder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	prompt = "> "
+
+	// ANSI escape sequences.
+	enterAltScreen = "\x1b[?1049h"
+	leaveAltScreen = "\x1b[?1049l"
+	cursorHome     = "\x1b[H"
+	clearLine      = "\x1b[K"
+	clearBelow     = "\x1b[J"
+	styleReset     = "\x1b[0m"
+	styleMatched   = "\x1b[1;32m"
+	styleCursor    = "\x1b[1;7m"
+	styleInfo      = "\x1b[2m"
+
+	// The preview is shown if the terminal is wide enough.
+	minPreviewWidth = 60
+	headerLines     = 2
+
+	// The size is used if the terminal does not report its size.
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+var ErrNoTerminal = errors.New("not a terminal")
+
+// Action represents a shell command bound to a key.
+// "{}" in the command is replaced with the quoted selected item.
+type Action struct {
+	Key     Key
+	Command string
+	// Abort reports whether the finder exits after executing the command.
+	Abort bool
+	// Interactive reports whether the command runs on the terminal and the finder resumes after it.
+	Interactive bool
+}
+
+// Finder represents an interactive fuzzy finder.
+type Finder struct {
+	items    []string
+	preview  func(item string) string
+	actions  map[Key]Action
+	previews map[string][]string
+
+	// execute executes the command of the action, which is replaced for test.
+	execute func(command string, stdio *os.File) error
+}
+
+// result represents the result of the event loop.
+type result struct {
+	item   string
+	action *Action
+}
+
+// Run runs the finder on the terminal and returns the selected item.
+// It returns an empty string if the finder is aborted.
+func (f *Finder) Run(tty *os.File) (string, error) {
+	fd := int(tty.Fd())
+	if !term.IsTerminal(fd) {
+		return "", ErrNoTerminal
+	}
+
+	size := func() (int, int, error) {
+		width, height, err := term.GetSize(fd)
+		if err == nil && (width <= 0 || height <= 0) {
+			return defaultWidth, defaultHeight, nil
+		}
+		return width, height, err
+	}
+
+	m := newModel(f.items)
+	for {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return "", fmt.Errorf("terminal raw mode: %w", err)
+		}
+		fmt.Fprint(tty, enterAltScreen)
+
+		res, err := f.loop(m, tty, tty, size)
+		fmt.Fprint(tty, leaveAltScreen)
+		term.Restore(fd, state) //nolint:errcheck
+		if err != nil {
+			return "", err
+		}
+
+		if res.action == nil {
+			return res.item, nil
+		}
+
+		// The command for the terminal runs after the terminal is restored.
+		err = f.execute(f.command(*res.action, res.item), tty)
+		if res.action.Abort {
+			return "", err
+		}
+	}
+}
+
+// loop handles input events until an item is selected or the finder is aborted.
+// The model keeps the query and the cursor while interactive commands are executed.
+func (f *Finder) loop(m *model, in io.Reader, out io.Writer, size func() (int, int, error)) (result, error) {
+	buf := make([]byte, 256)
+
+	for {
+		width, height, err := size()
+		if err != nil {
+			return result{}, fmt.Errorf("terminal size: %w", err)
+		}
+		f.render(out, m, width, height)
+
+		n, err := in.Read(buf)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return result{}, nil
+			}
+			return result{}, fmt.Errorf("read terminal: %w", err)
+		}
+
+		for _, ev := range decodeEvents(buf[:n]) {
+			if action, ok := f.actions[ev.key]; ok {
+				item, ok := m.current()
+				if !ok {
+					continue
+				}
+				if action.Abort || action.Interactive {
+					return result{item: item, action: &action}, nil
+				}
+				// Errors of silent commands are ignored as fzf does.
+				f.execute(f.command(action, item), nil) //nolint:errcheck
+				continue
+			}
+
+			switch m.handle(ev, height-headerLines) {
+			case accepted:
+				item, _ := m.current()
+				return result{item: item}, nil
+			case aborted:
+				return result{}, nil
+			}
+		}
+	}
+}
+
+// command returns the command of the action for the item.
+func (f *Finder) command(action Action, item string) string {
+	return strings.ReplaceAll(action.Command, "{}", ShellQuote(item))
+}
+
+// previewLines returns the lines of the preview of the item.
+func (f *Finder) previewLines(item string) []string {
+	if f.preview == nil {
+		return nil
+	}
+
+	lines, ok := f.previews[item]
+	if !ok {
+		lines = strings.Split(strings.TrimRight(f.preview(item), "\n"), "\n")
+		f.previews[item] = lines
+	}
+
+	return lines
+}
+
+// render renders the finder on the terminal.
+func (f *Finder) render(w io.Writer, m *model, width, height int) {
+	listWidth := width
+	previewWidth := 0
+	if width >= minPreviewWidth && f.preview != nil {
+		listWidth = width / 2
+		previewWidth = width - listWidth - 1
+	}
+
+	var preview []string
+	if item, ok := m.current(); ok && previewWidth > 0 {
+		preview = f.previewLines(item)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(cursorHome)
+
+	for row := range height {
+		var left string
+		switch row {
+		case 0:
+			left = truncate(prompt+string(m.query), listWidth)
+		case 1:
+			left = styleInfo + truncate(fmt.Sprintf("  %d/%d", len(m.matches), len(m.items)), listWidth) + styleReset
+		default:
+			left = m.renderItem(row-headerLines+m.offset, listWidth)
+		}
+		sb.WriteString(left)
+
+		if previewWidth > 0 {
+			sb.WriteString(strings.Repeat(" ", max(listWidth-visibleWidth(left), 0)))
+			sb.WriteString("│")
+			if row < len(preview) {
+				sb.WriteString(truncate(expandTabs(preview[row]), previewWidth))
+			}
+		}
+
+		sb.WriteString(clearLine)
+		if row < height-1 {
+			sb.WriteString("\r\n")
+		}
+	}
+
+	sb.WriteString(clearBelow)
+	// Place the cursor at the end of the query.
+	fmt.Fprintf(&sb, "\x1b[1;%dH", min(textWidth(prompt+string(m.query))+1, listWidth))
+
+	fmt.Fprint(w, sb.String())
+}
+
+// New returns a finder of the items.
+// The preview returns the contents to be shown beside the items, which can be nil.
+func New(items []string, preview func(item string) string, actions []Action) *Finder {
+	f := &Finder{
+		items:    items,
+		preview:  preview,
+		actions:  make(map[Key]Action),
+		previews: make(map[string][]string),
+		execute:  execute,
+	}
+
+	for _, action := range actions {
+		f.actions[action.Key] = action
+	}
+
+	return f
+}
+
+// execute executes the shell command.
+// The command is connected to the terminal if stdio is given, otherwise its output is discarded.
+func execute(command string, stdio *os.File) error {
+	cmd := exec.Command("sh", "-c", command)
+	if stdio != nil {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = stdio, stdio, stdio
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("execute command: %w", err)
+	}
+
+	return nil
+}
+
+// ShellQuote returns the string quoted for POSIX shells.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
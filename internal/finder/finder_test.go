@@ -0,0 +1,108 @@
+package finder_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yendo/fcqs/internal/finder"
+)
+
+var items = []string{"git log", "go test", "docker logs", "it's quoted"}
+
+func TestFinderLoop(t *testing.T) {
+	t.Parallel()
+
+	actions := []finder.Action{
+		{Key: "ctrl-y", Command: "copy {}"},
+		{Key: "ctrl-e", Command: "edit {}", Abort: true},
+		{Key: "ctrl-o", Command: "open {}", Interactive: true},
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		item     string
+		commands []string
+	}{
+		{name: "enter", input: "\r", item: "git log"},
+		{name: "query", input: "test\r", item: "go test"},
+		{name: "move down", input: "\x1b[B\x1b[B\r", item: "docker logs"},
+		{name: "move over the end", input: "\x1b[B\x1b[B\x1b[B\x1b[B\x1b[B\r", item: "it's quoted"},
+		{name: "move up", input: "\x0e\x0e\x10\r", item: "go test"},
+		{name: "backspace", input: "dockx\x7f\r", item: "docker logs"},
+		{name: "clear query", input: "xyz\x15\r", item: "git log"},
+		{name: "delete word", input: "go xyz\x17\r", item: "go test"},
+		{name: "no match", input: "xyz\r"},
+		{name: "esc", input: "\x1b"},
+		{name: "ctrl-c", input: "\x03"},
+		{name: "eof", input: "go"},
+		{name: "silent action", input: "\x19\x0e\x19\r", item: "go test", commands: []string{"copy 'git log'", "copy 'go test'"}},
+		{name: "abort action", input: "quoted\x05", commands: []string{`edit 'it'\''s quoted'`}},
+		{name: "interactive action", input: "docker\x0f", commands: []string{"open 'docker logs'"}},
+		{name: "action without match", input: "xyz\x19\x1b"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := finder.New(items, nil, actions)
+			var out bytes.Buffer
+
+			item, commands, err := f.Loop(strings.NewReader(tc.input), &out, 40, 10)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.item, item)
+			assert.Equal(t, tc.commands, commands)
+		})
+	}
+}
+
+func TestFinderRender(t *testing.T) {
+	t.Parallel()
+
+	preview := func(item string) string {
+		return "# " + item + "\n\ncontents\n"
+	}
+
+	t.Run("with preview", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+		_, _, err := finder.New(items, preview, nil).Loop(strings.NewReader("go"), &out, 80, 10)
+
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "│# git log")
+		assert.Contains(t, out.String(), "│contents")
+		assert.Contains(t, out.String(), "│# go test")
+		assert.Contains(t, out.String(), "  2/4")
+	})
+
+	t.Run("narrow terminal", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+		_, _, err := finder.New(items, preview, nil).Loop(strings.NewReader(""), &out, 40, 10)
+
+		require.NoError(t, err)
+		assert.NotContains(t, out.String(), "│")
+		assert.Contains(t, out.String(), "  4/4")
+	})
+}
+
+func TestFinderRunNoTerminal(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.CreateTemp(t.TempDir(), "tty")
+	require.NoError(t, err)
+	defer f.Close()
+
+	item, err := finder.New(items, nil, nil).Run(f)
+
+	require.ErrorIs(t, err, finder.ErrNoTerminal)
+	assert.Empty(t, item)
+}
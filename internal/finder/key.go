@@ -0,0 +1,146 @@
+package finder
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"unicode/utf8"
+)
+
+// Key represents a key name in the same notation as fzf, e.g. "ctrl-y" and "alt-x".
+type Key string
+
+const (
+	KeyEnter     Key = "enter"
+	KeyEsc       Key = "esc"
+	KeyTab       Key = "tab"
+	KeyBTab      Key = "btab"
+	KeyBackspace Key = "bspace"
+	KeyDelete    Key = "del"
+	KeyUp        Key = "up"
+	KeyDown      Key = "down"
+	KeyLeft      Key = "left"
+	KeyRight     Key = "right"
+	KeyHome      Key = "home"
+	KeyEnd       Key = "end"
+	KeyPageUp    Key = "pgup"
+	KeyPageDown  Key = "pgdn"
+)
+
+const (
+	ctrlKeyPrefix = "ctrl-"
+	altKeyPrefix  = "alt-"
+
+	esc       = 0x1b
+	del       = 0x7f
+	backspace = 0x08
+	tab       = 0x09
+	enter     = 0x0d
+)
+
+var ErrUnknownKey = errors.New("unknown key")
+
+var namedKeys = []Key{
+	KeyEnter, KeyEsc, KeyTab, KeyBTab, KeyBackspace, KeyDelete,
+	KeyUp, KeyDown, KeyLeft, KeyRight, KeyHome, KeyEnd, KeyPageUp, KeyPageDown,
+}
+
+// escapeSequences maps escape sequences after ESC to keys.
+var escapeSequences = map[string]Key{
+	"[A": KeyUp, "[B": KeyDown, "[C": KeyRight, "[D": KeyLeft,
+	"OA": KeyUp, "OB": KeyDown, "OC": KeyRight, "OD": KeyLeft,
+	"[H": KeyHome, "[F": KeyEnd, "OH": KeyHome, "OF": KeyEnd,
+	"[1~": KeyHome, "[4~": KeyEnd, "[3~": KeyDelete,
+	"[5~": KeyPageUp, "[6~": KeyPageDown, "[Z": KeyBTab,
+}
+
+// event represents an input event of a key or a character.
+type event struct {
+	key Key
+	ch  rune
+}
+
+// ParseKey returns the key of the name.
+func ParseKey(name string) (Key, error) {
+	key := Key(name)
+
+	if slices.Contains(namedKeys, key) {
+		return key, nil
+	}
+
+	for _, prefix := range []string{ctrlKeyPrefix, altKeyPrefix} {
+		if len(name) == len(prefix)+1 && name[:len(prefix)] == prefix {
+			c := name[len(prefix)]
+			if 'a' <= c && c <= 'z' || prefix == altKeyPrefix && '0' <= c && c <= '9' {
+				return key, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownKey, name)
+}
+
+// decodeEvents returns the input events in the bytes read from a terminal.
+func decodeEvents(b []byte) []event {
+	var events []event
+
+	for len(b) > 0 {
+		ev, n := decodeEvent(b)
+		events = append(events, ev)
+		b = b[n:]
+	}
+
+	return events
+}
+
+// decodeEvent returns the first input event in the bytes and its length.
+func decodeEvent(b []byte) (event, int) {
+	switch c := b[0]; {
+	case c == esc:
+		return decodeEscape(b)
+	case c == enter:
+		return event{key: KeyEnter}, 1
+	case c == tab:
+		return event{key: KeyTab}, 1
+	case c == del || c == backspace:
+		return event{key: KeyBackspace}, 1
+	case c < ' ':
+		return event{key: Key(ctrlKeyPrefix + string(rune('a'+c-1)))}, 1
+	}
+
+	r, n := utf8.DecodeRune(b)
+
+	return event{ch: r}, n
+}
+
+// decodeEscape returns the input event of the escape sequence and its length.
+func decodeEscape(b []byte) (event, int) {
+	if len(b) == 1 {
+		return event{key: KeyEsc}, 1
+	}
+
+	for seq, key := range escapeSequences {
+		if len(b) > len(seq) && string(b[1:len(seq)+1]) == seq {
+			return event{key: key}, len(seq) + 1
+		}
+	}
+
+	if b[1] == '[' || b[1] == 'O' {
+		// Ignore unknown sequences up to the final byte.
+		for i := 2; i < len(b); i++ {
+			if '@' <= b[i] && b[i] <= '~' {
+				return event{}, i + 1
+			}
+		}
+
+		return event{}, len(b)
+	}
+
+	if b[1] == esc {
+		return event{key: KeyEsc}, 1
+	}
+
+	r, n := utf8.DecodeRune(b[1:])
+
+	return event{key: Key(altKeyPrefix + string(r))}, n + 1
+}
@@ -0,0 +1,92 @@
+package finder_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yendo/fcqs/internal/finder"
+)
+
+func TestParseKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		expect finder.Key
+	}{
+		{name: "ctrl-y", expect: "ctrl-y"},
+		{name: "alt-x", expect: "alt-x"},
+		{name: "alt-1", expect: "alt-1"},
+		{name: "enter", expect: finder.KeyEnter},
+		{name: "pgdn", expect: finder.KeyPageDown},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			key, err := finder.ParseKey(tc.name)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, key)
+		})
+	}
+}
+
+func TestParseKeyFail(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"", "ctrl-", "ctrl-1", "ctrl-yy", "shift-a", "f13"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			key, err := finder.ParseKey(name)
+
+			require.ErrorIs(t, err, finder.ErrUnknownKey)
+			assert.Empty(t, key)
+		})
+	}
+}
+
+func TestDecodeEvents(t *testing.T) {
+	t.Parallel()
+
+	type event struct {
+		key finder.Key
+		ch  rune
+	}
+
+	tests := []struct {
+		name   string
+		input  string
+		expect []event
+	}{
+		{name: "characters", input: "aあ", expect: []event{{ch: 'a'}, {ch: 'あ'}}},
+		{name: "enter", input: "\r", expect: []event{{key: finder.KeyEnter}}},
+		{name: "tab", input: "\t", expect: []event{{key: finder.KeyTab}}},
+		{name: "backspace", input: "\x7f\x08", expect: []event{{key: finder.KeyBackspace}, {key: finder.KeyBackspace}}},
+		{name: "ctrl", input: "\x19", expect: []event{{key: "ctrl-y"}}},
+		{name: "esc", input: "\x1b", expect: []event{{key: finder.KeyEsc}}},
+		{name: "double esc", input: "\x1b\x1b", expect: []event{{key: finder.KeyEsc}, {key: finder.KeyEsc}}},
+		{name: "alt", input: "\x1bx", expect: []event{{key: "alt-x"}}},
+		{name: "arrows", input: "\x1b[A\x1bOB", expect: []event{{key: finder.KeyUp}, {key: finder.KeyDown}}},
+		{name: "page down", input: "\x1b[6~", expect: []event{{key: finder.KeyPageDown}}},
+		{name: "btab", input: "\x1b[Z", expect: []event{{key: finder.KeyBTab}}},
+		{name: "unknown sequence", input: "\x1b[15~a", expect: []event{{}, {ch: 'a'}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []event
+			for _, ev := range finder.ExportDecodeEvents([]byte(tc.input)) {
+				key, ch := finder.ExportEvent(ev)
+				got = append(got, event{key: key, ch: ch})
+			}
+
+			assert.Equal(t, tc.expect, got)
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package finder
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	// Bonuses for the matched character depending on the previous character.
+	bonusBoundaryWhite = 10
+	bonusBoundary      = 8
+	bonusNonWord       = 8
+	bonusCamelCase     = 7
+	bonusConsecutive   = 4
+	bonusFirstCharMult = 2
+)
+
+// charClass represents a class of characters for bonuses.
+type charClass int
+
+const (
+	charWhite charClass = iota
+	charNonWord
+	charLower
+	charUpper
+	charLetter
+	charNumber
+)
+
+// Match represents a matched item.
+type Match struct {
+	Item      string
+	Index     int
+	Score     int
+	Positions []int
+}
+
+// Filter returns the items matched with the query sorted by score.
+// The query is split into terms by spaces, and every term must match.
+// Items are sorted by score, length and original order.
+func Filter(items []string, query string) []Match {
+	terms := strings.Fields(query)
+
+	matches := make([]Match, 0, len(items))
+	for i, item := range items {
+		if m, ok := matchTerms(item, terms); ok {
+			m.Index = i
+			matches = append(matches, m)
+		}
+	}
+
+	if len(terms) == 0 {
+		return matches
+	}
+
+	slices.SortStableFunc(matches, func(a, b Match) int {
+		if a.Score != b.Score {
+			return b.Score - a.Score
+		}
+
+		return len(a.Item) - len(b.Item)
+	})
+
+	return matches
+}
+
+// matchTerms returns the match of all terms in the item.
+func matchTerms(item string, terms []string) (Match, bool) {
+	m := Match{Item: item}
+	text := []rune(item)
+
+	for _, term := range terms {
+		score, positions, ok := matchTerm(text, []rune(term))
+		if !ok {
+			return Match{}, false
+		}
+		m.Score += score
+		m.Positions = append(m.Positions, positions...)
+	}
+
+	slices.Sort(m.Positions)
+	m.Positions = slices.Compact(m.Positions)
+
+	return m, true
+}
+
+// matchTerm returns the score and the rune positions of the fuzzy match of the pattern in the text.
+// The match is case-insensitive unless the pattern has upper case characters.
+func matchTerm(text, pattern []rune) (int, []int, bool) {
+	caseSensitive := slices.ContainsFunc(pattern, unicode.IsUpper)
+	equal := func(t, p rune) bool {
+		if caseSensitive {
+			return t == p
+		}
+
+		return unicode.ToLower(t) == p
+	}
+
+	// Find the end of the first occurrence of the pattern.
+	end, pidx := -1, 0
+	for i, r := range text {
+		if equal(r, pattern[pidx]) {
+			pidx++
+			if pidx == len(pattern) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	// Find the start of the shortest occurrence ending there.
+	start, pidx := end, len(pattern)-1
+	for i := end; i >= 0; i-- {
+		if equal(text[i], pattern[pidx]) {
+			pidx--
+			if pidx < 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	score, positions := calculateScore(text, pattern, start, end, equal)
+
+	return score, positions, true
+}
+
+// calculateScore returns the score and the positions of the match in the range of the text.
+func calculateScore(text, pattern []rune, start, end int, equal func(t, p rune) bool) (int, []int) {
+	positions := make([]int, 0, len(pattern))
+	score, pidx, consecutive, firstBonus := 0, 0, 0, 0
+	inGap := false
+
+	prevClass := charWhite
+	if start > 0 {
+		prevClass = classOf(text[start-1])
+	}
+
+	for i := start; i <= end && pidx < len(pattern); i++ {
+		class := classOf(text[i])
+
+		if equal(text[i], pattern[pidx]) {
+			positions = append(positions, i)
+			score += scoreMatch
+
+			bonus := bonusFor(prevClass, class)
+			if consecutive == 0 {
+				firstBonus = bonus
+			} else {
+				if bonus >= bonusBoundary && bonus > firstBonus {
+					firstBonus = bonus
+				}
+				bonus = max(bonus, firstBonus, bonusConsecutive)
+			}
+
+			if pidx == 0 {
+				score += bonus * bonusFirstCharMult
+			} else {
+				score += bonus
+			}
+
+			inGap = false
+			consecutive++
+			pidx++
+		} else {
+			if inGap {
+				score += scoreGapExtension
+			} else {
+				score += scoreGapStart
+			}
+
+			inGap = true
+			consecutive, firstBonus = 0, 0
+		}
+
+		prevClass = class
+	}
+
+	return score, positions
+}
+
+// classOf returns the class of the character.
+func classOf(r rune) charClass {
+	switch {
+	case unicode.IsSpace(r):
+		return charWhite
+	case unicode.IsLower(r):
+		return charLower
+	case unicode.IsUpper(r):
+		return charUpper
+	case unicode.IsNumber(r):
+		return charNumber
+	case unicode.IsLetter(r):
+		return charLetter
+	default:
+		return charNonWord
+	}
+}
+
+// bonusFor returns the bonus for the character of the class after the character of the previous class.
+func bonusFor(prevClass, class charClass) int {
+	if class > charNonWord {
+		switch {
+		case prevClass == charWhite:
+			return bonusBoundaryWhite
+		case prevClass == charNonWord:
+			return bonusBoundary
+		case prevClass == charLower && class == charUpper,
+			prevClass != charNumber && class == charNumber:
+			return bonusCamelCase
+		}
+	}
+
+	if class == charNonWord {
+		return bonusNonWord
+	}
+
+	return 0
+}
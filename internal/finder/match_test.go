@@ -0,0 +1,64 @@
+package finder_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yendo/fcqs/internal/finder"
+)
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	items := []string{"git log", "go test", "Get URL", "docker logs", "golang"}
+
+	tests := []struct {
+		name   string
+		query  string
+		expect []string
+	}{
+		{name: "empty query", query: "", expect: items},
+		{name: "spaces only", query: "  ", expect: items},
+		{name: "fuzzy", query: "gt", expect: []string{"go test", "git log", "Get URL"}},
+		{name: "case insensitive", query: "get", expect: []string{"Get URL", "go test"}},
+		{name: "smart case", query: "Ge", expect: []string{"Get URL"}},
+		{name: "consecutive first", query: "log", expect: []string{"git log", "docker logs"}},
+		{name: "and terms", query: "go te", expect: []string{"go test"}},
+		{name: "no match", query: "xyz", expect: []string{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			matches := finder.Filter(items, tc.query)
+
+			got := make([]string, 0, len(matches))
+			for _, m := range matches {
+				got = append(got, m.Item)
+			}
+			assert.Equal(t, tc.expect, got)
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	t.Parallel()
+
+	matches := finder.Filter([]string{"abc", "xaxbxc"}, "abc")
+
+	assert.Equal(t, []finder.Match{
+		{Item: "abc", Index: 0, Score: matches[0].Score, Positions: []int{0, 1, 2}},
+		{Item: "xaxbxc", Index: 1, Score: matches[1].Score, Positions: []int{1, 3, 5}},
+	}, matches)
+	assert.Greater(t, matches[0].Score, matches[1].Score)
+}
+
+func TestFilterWide(t *testing.T) {
+	t.Parallel()
+
+	matches := finder.Filter([]string{"日本語のメモ"}, "日メ")
+
+	assert.Len(t, matches, 1)
+	assert.Equal(t, []int{0, 4}, matches[0].Positions)
+}
@@ -0,0 +1,143 @@
+package finder
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	cursorMark = "> "
+	noMark     = "  "
+)
+
+// handleResult represents the result of handling an event.
+type handleResult int
+
+const (
+	continued handleResult = iota
+	accepted
+	aborted
+)
+
+// model represents the state of the finder.
+type model struct {
+	items   []string
+	query   []rune
+	matches []Match
+	cursor  int
+	offset  int
+}
+
+// current returns the item under the cursor.
+func (m *model) current() (string, bool) {
+	if m.cursor >= len(m.matches) {
+		return "", false
+	}
+
+	return m.matches[m.cursor].Item, true
+}
+
+// filter updates the matched items with the query.
+func (m *model) filter() {
+	m.matches = Filter(m.items, string(m.query))
+	m.cursor, m.offset = 0, 0
+}
+
+// move moves the cursor and scrolls the list to show the cursor.
+func (m *model) move(delta, listHeight int) {
+	m.cursor = max(min(m.cursor+delta, len(m.matches)-1), 0)
+
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if listHeight > 0 && m.cursor >= m.offset+listHeight {
+		m.offset = m.cursor - listHeight + 1
+	}
+}
+
+// handle updates the state with the event.
+func (m *model) handle(ev event, listHeight int) handleResult {
+	if ev.key == "" {
+		if ev.ch != 0 && unicode.IsPrint(ev.ch) {
+			m.query = append(m.query, ev.ch)
+			m.filter()
+		}
+
+		return continued
+	}
+
+	switch ev.key {
+	case KeyEnter:
+		if _, ok := m.current(); ok {
+			return accepted
+		}
+	case KeyEsc, "ctrl-c", "ctrl-g", "ctrl-q":
+		return aborted
+	case KeyBackspace, "ctrl-h":
+		if len(m.query) > 0 {
+			m.query = m.query[:len(m.query)-1]
+			m.filter()
+		}
+	case "ctrl-u":
+		m.query = nil
+		m.filter()
+	case "ctrl-w":
+		query := strings.TrimRightFunc(string(m.query), unicode.IsSpace)
+		i := strings.LastIndexFunc(query, unicode.IsSpace)
+		m.query = []rune(query[:i+1])
+		m.filter()
+	case KeyUp, KeyBTab, "ctrl-p", "ctrl-k":
+		m.move(-1, listHeight)
+	case KeyDown, KeyTab, "ctrl-n", "ctrl-j":
+		m.move(1, listHeight)
+	case KeyPageUp:
+		m.move(-listHeight, listHeight)
+	case KeyPageDown:
+		m.move(listHeight, listHeight)
+	}
+
+	return continued
+}
+
+// renderItem returns the i-th matched item with the highlighted matched characters.
+func (m *model) renderItem(i, width int) string {
+	if i >= len(m.matches) {
+		return ""
+	}
+
+	match := m.matches[i]
+	mark, style := noMark, ""
+	if i == m.cursor {
+		mark, style = cursorMark, styleCursor
+	}
+
+	var sb strings.Builder
+	sb.WriteString(style + mark)
+
+	w := textWidth(mark)
+	for j, r := range []rune(match.Item) {
+		rw := runeWidth(r)
+		if w+rw > width {
+			break
+		}
+		w += rw
+
+		if slices.Contains(match.Positions, j) {
+			sb.WriteString(styleMatched + string(r) + styleReset + style)
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteString(styleReset)
+
+	return sb.String()
+}
+
+// newModel returns a model of the items.
+func newModel(items []string) *model {
+	m := &model{items: items}
+	m.filter()
+
+	return m
+}
@@ -0,0 +1,78 @@
+package finder
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const tabWidth = 4
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;?]*[A-Za-z]")
+
+// wideRanges are ranges of East Asian wide and fullwidth characters.
+var wideRanges = []struct{ first, last rune }{
+	{0x1100, 0x115F},   // Hangul Jamo
+	{0x2E80, 0x303E},   // CJK Radicals, Kangxi Radicals, CJK Symbols and Punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, CJK Compatibility
+	{0x3400, 0x4DBF},   // CJK Unified Ideographs Extension A
+	{0x4E00, 0x9FFF},   // CJK Unified Ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xAC00, 0xD7A3},   // Hangul Syllables
+	{0xF900, 0xFAFF},   // CJK Compatibility Ideographs
+	{0xFE30, 0xFE4F},   // CJK Compatibility Forms
+	{0xFF00, 0xFF60},   // Fullwidth Forms
+	{0xFFE0, 0xFFE6},   // Fullwidth Signs
+	{0x1F300, 0x1F64F}, // Miscellaneous Symbols and Pictographs, Emoticons
+	{0x1F900, 0x1F9FF}, // Supplemental Symbols and Pictographs
+	{0x20000, 0x3FFFD}, // CJK Unified Ideographs Extension B and later
+}
+
+// runeWidth returns the number of columns of the character on a terminal.
+func runeWidth(r rune) int {
+	if !unicode.IsPrint(r) || unicode.Is(unicode.Mn, r) {
+		return 0
+	}
+
+	for _, wr := range wideRanges {
+		if wr.first <= r && r <= wr.last {
+			return 2
+		}
+	}
+
+	return 1
+}
+
+// textWidth returns the number of columns of the text without escape sequences.
+func textWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		w += runeWidth(r)
+	}
+
+	return w
+}
+
+// visibleWidth returns the number of columns of the text with escape sequences.
+func visibleWidth(s string) int {
+	return textWidth(ansiEscape.ReplaceAllString(s, ""))
+}
+
+// truncate returns the text truncated to the number of columns.
+func truncate(s string, width int) string {
+	w := 0
+	for i, r := range s {
+		rw := runeWidth(r)
+		if w+rw > width {
+			return s[:i]
+		}
+		w += rw
+	}
+
+	return s
+}
+
+// expandTabs returns the line whose tabs are replaced with spaces.
+func expandTabs(line string) string {
+	return strings.ReplaceAll(line, "\t", strings.Repeat(" ", tabWidth))
+}
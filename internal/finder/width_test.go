@@ -0,0 +1,57 @@
+package finder_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yendo/fcqs/internal/finder"
+)
+
+func TestTextWidth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		text   string
+		expect int
+	}{
+		{name: "ascii", text: "abc", expect: 3},
+		{name: "wide", text: "日本語", expect: 6},
+		{name: "mixed", text: "aあb", expect: 4},
+		{name: "control", text: "a\x1b", expect: 1},
+		{name: "empty", text: "", expect: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expect, finder.ExportTextWidth(tc.text))
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		text   string
+		width  int
+		expect string
+	}{
+		{name: "short", text: "abc", width: 5, expect: "abc"},
+		{name: "just", text: "abc", width: 3, expect: "abc"},
+		{name: "long", text: "abcdef", width: 3, expect: "abc"},
+		{name: "wide", text: "日本語", width: 5, expect: "日本"},
+		{name: "zero", text: "abc", width: 0, expect: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expect, finder.ExportTruncate(tc.text, tc.width))
+		})
+	}
+}
@@ -1,44 +1,126 @@
 package value
 
 import (
-	"slices"
+	"maps"
 	"strings"
 )
 
-const codeFence = "```"
+const (
+	backtickFenceChar = '`'
+	tildeFenceChar    = '~'
+	minFenceLength    = 3
+)
+
+// GenericShell is the language of fenced code blocks for any POSIX shell.
+const GenericShell = "sh"
+
+// shellLanguages maps the identifiers of the info string to the shell languages.
+var shellLanguages = map[string]string{
+	"shell":        GenericShell,
+	"sh":           GenericShell,
+	"shell-script": GenericShell,
+	"shellsession": GenericShell,
+	"console":      GenericShell,
+	"bash":         "bash",
+	"zsh":          "zsh",
+	"fish":         "fish",
+	"powershell":   "pwsh",
+	"posh":         "pwsh",
+	"pwsh":         "pwsh",
+}
 
-var shellList = []string{
-	"shell", "sh", "shell-script", "bash", "zsh",
-	"powershell", "posh", "pwsh",
-	"shellsession", "console",
+// AddShellLanguage adds the identifier of the info string for the shell language.
+func AddShellLanguage(id, language string) {
+	shellLanguages[id] = language
+}
+
+// ShellLanguages returns the identifiers of the info string and their shell languages.
+func ShellLanguages() map[string]string {
+	return maps.Clone(shellLanguages)
 }
 
 // FenceLine represents a fence text line.
 type FenceLine struct {
-	line string
+	char   byte
+	length int
+	info   string
+}
+
+// Char returns the fence character, which is ` or ~.
+func (fl FenceLine) Char() byte {
+	return fl.char
+}
+
+// Len returns the number of fence characters.
+func (fl FenceLine) Len() int {
+	return fl.length
 }
 
 // HasShellID reports whether the fence line has shell identifier.
 func (fl FenceLine) HasShellID() bool {
-	trimmedLine := strings.Trim(fl.line, "` ")
-	if trimmedLine == "" {
+	return fl.Language() != ""
+}
+
+// Language returns the shell language detected from the identifier of the fence line,
+// which is sh, bash, zsh, fish, pwsh or an added language. It returns an empty string for other languages.
+func (fl FenceLine) Language() string {
+	return shellLanguages[fl.ID()]
+}
+
+// ID returns the identifier of the fence line, which is the first word of the info string.
+func (fl FenceLine) ID() string {
+	id, _, _ := strings.Cut(fl.info, " ")
+
+	return id
+}
+
+// IsTranscript reports whether the fence line has identifier of console transcripts.
+func (fl FenceLine) IsTranscript() bool {
+	id := fl.ID()
+
+	return id == "console" || id == "shellsession"
+}
+
+// IsClosedBy reports whether the line closes the fenced code block opened by the fence line.
+// The closing fence must use the same character, be at least as long and have no info string.
+func (fl FenceLine) IsClosedBy(line string) bool {
+	closing, ok := NewFenceLine(line)
+	if !ok {
 		return false
 	}
 
-	id := strings.Split(trimmedLine, " ")
-	return slices.Contains(shellList, id[0])
+	return closing.char == fl.char && closing.length >= fl.length && closing.info == ""
 }
 
 // NewFenceLine returns Fence line.
 func NewFenceLine(line string) (*FenceLine, bool) {
-	if !IsFenceLine(line) {
+	if line == "" {
 		return nil, false
 	}
 
-	return &FenceLine{line: line}, true
+	char := line[0]
+	if char != backtickFenceChar && char != tildeFenceChar {
+		return nil, false
+	}
+
+	length := len(line) - len(strings.TrimLeft(line, string(char)))
+	if length < minFenceLength {
+		return nil, false
+	}
+
+	info := strings.Trim(line[length:], " ")
+
+	// The info string of a backtick fence cannot contain backticks.
+	if char == backtickFenceChar && strings.ContainsRune(info, backtickFenceChar) {
+		return nil, false
+	}
+
+	return &FenceLine{char: char, length: length, info: info}, true
 }
 
 // IsFenceLine reports whether the line is fence line.
 func IsFenceLine(line string) bool {
-	return strings.HasPrefix(line, codeFence)
+	_, ok := NewFenceLine(line)
+
+	return ok
 }
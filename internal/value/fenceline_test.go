@@ -24,11 +24,14 @@ func TestFenceLineHasShellID(t *testing.T) {
 		{name: "shell-script", line: "``` shell-script", expect: true},
 		{name: "bash", line: "``` bash", expect: true},
 		{name: "zsh", line: "``` zsh", expect: true},
+		{name: "fish", line: "``` fish", expect: true},
 		{name: "powershell", line: "``` powershell", expect: true},
 		{name: "posh", line: "``` posh", expect: true},
 		{name: "pwsh", line: "``` pwsh", expect: true},
 		{name: "shellsession", line: "``` shellsession", expect: true},
 		{name: "console", line: "``` console", expect: true},
+		{name: "tilde shell", line: "~~~ shell", expect: true},
+		{name: "long fence shell", line: "````shell", expect: true},
 		{name: "go", line: "``` go", expect: false},
 		{name: "no identifier", line: "```", expect: false},
 		{name: "other identifier", line: "``` other", expect: false},
@@ -45,6 +48,86 @@ func TestFenceLineHasShellID(t *testing.T) {
 	}
 }
 
+func TestFenceLineLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		line   string
+		expect string
+	}{
+		{name: "shell", line: "``` shell", expect: "sh"},
+		{name: "console", line: "```console", expect: "sh"},
+		{name: "bash + string", line: "``` bash xxxx", expect: "bash"},
+		{name: "zsh", line: "```zsh", expect: "zsh"},
+		{name: "fish", line: "~~~ fish", expect: "fish"},
+		{name: "powershell", line: "``` powershell", expect: "pwsh"},
+		{name: "posh", line: "```posh", expect: "pwsh"},
+		{name: "go", line: "``` go", expect: ""},
+		{name: "no identifier", line: "```", expect: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fenceLine, ok := value.NewFenceLine(tc.line)
+
+			require.True(t, ok)
+			assert.Equal(t, tc.expect, fenceLine.Language())
+		})
+	}
+}
+
+func TestFenceLineID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		line   string
+		expect string
+	}{
+		{name: "go", line: "```go", expect: "go"},
+		{name: "with string", line: "``` bash session", expect: "bash"},
+		{name: "no identifier", line: "~~~", expect: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fenceLine, ok := value.NewFenceLine(tc.line)
+
+			require.True(t, ok)
+			assert.Equal(t, tc.expect, fenceLine.ID())
+		})
+	}
+}
+
+func TestFenceLineIsTranscript(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		line   string
+		expect bool
+	}{
+		{name: "console", line: "``` console", expect: true},
+		{name: "shellsession", line: "```shellsession", expect: true},
+		{name: "bash session", line: "``` bash session", expect: false},
+		{name: "sh", line: "```sh", expect: false},
+		{name: "no identifier", line: "```", expect: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fenceLine, ok := value.NewFenceLine(tc.line)
+
+			require.True(t, ok)
+			assert.Equal(t, tc.expect, fenceLine.IsTranscript())
+		})
+	}
+}
+
 func TestFenceLineFuncs(t *testing.T) {
 	t.Parallel()
 
@@ -57,6 +140,13 @@ func TestFenceLineFuncs(t *testing.T) {
 		{name: "with trailing spaces", line: "```  ", expect: true},
 		{name: "with identifier", line: "``` go", expect: true},
 		{name: "with long identifier", line: "``` shell console", expect: true},
+		{name: "tilde", line: "~~~", expect: true},
+		{name: "tilde with identifier", line: "~~~ go", expect: true},
+		{name: "long fence", line: "`````", expect: true},
+		{name: "tilde with backtick in identifier", line: "~~~ go`", expect: true},
+		{name: "backtick in identifier", line: "``` go`", expect: false},
+		{name: "no enough tilde fence", line: "~~", expect: false},
+		{name: "mixed fence chars", line: "`~`", expect: false},
 		{name: "no fence", line: "no fence", expect: false},
 		{name: "no enough fence", line: "``", expect: false},
 		{name: "head with spaces", line: " ```", expect: false},
@@ -91,3 +181,64 @@ func TestFenceLineFuncs(t *testing.T) {
 		}
 	})
 }
+
+func TestFenceLineCharAndLen(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		line   string
+		char   byte
+		length int
+	}{
+		{name: "backtick", line: "```", char: '`', length: 3},
+		{name: "long backtick", line: "`````go", char: '`', length: 5},
+		{name: "tilde", line: "~~~ sh", char: '~', length: 3},
+		{name: "long tilde", line: "~~~~", char: '~', length: 4},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fenceLine, ok := value.NewFenceLine(tc.line)
+
+			require.True(t, ok)
+			assert.Equal(t, tc.char, fenceLine.Char())
+			assert.Equal(t, tc.length, fenceLine.Len())
+		})
+	}
+}
+
+func TestFenceLineIsClosedBy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opening string
+		line    string
+		expect  bool
+	}{
+		{name: "same fence", opening: "```", line: "```", expect: true},
+		{name: "opening with identifier", opening: "```go", line: "```", expect: true},
+		{name: "longer fence", opening: "```", line: "`````", expect: true},
+		{name: "closing with trailing spaces", opening: "```", line: "```  ", expect: true},
+		{name: "same tilde fence", opening: "~~~", line: "~~~", expect: true},
+		{name: "shorter fence", opening: "````", line: "```", expect: false},
+		{name: "other fence char", opening: "~~~", line: "```", expect: false},
+		{name: "closing with identifier", opening: "```", line: "```go", expect: false},
+		{name: "not fence", opening: "```", line: "text", expect: false},
+		{name: "empty", opening: "```", line: "", expect: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fenceLine, ok := value.NewFenceLine(tc.opening)
+
+			require.True(t, ok)
+			assert.Equal(t, tc.expect, fenceLine.IsClosedBy(tc.line))
+		})
+	}
+}
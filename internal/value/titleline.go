@@ -1,12 +1,21 @@
 package value
 
-import "strings"
+import (
+	"regexp"
+	"strings"
+)
 
 const atxHeadingChar = "#"
 
+var (
+	setextUnderline = regexp.MustCompile(`^(=+|-+) *$`)
+	listItemMarker  = regexp.MustCompile(`^([-+*]|[0-9]+[.)])( |$)`)
+)
+
 // TitleLine represents a title text line that allows empty titles.
 type TitleLine struct {
 	title *Title
+	level int
 }
 
 // Title returns a title in the title line.
@@ -14,6 +23,11 @@ func (tl TitleLine) Title() Title {
 	return *tl.title
 }
 
+// Level returns the heading level of the title line.
+func (tl TitleLine) Level() int {
+	return tl.level
+}
+
 // HasValidTitle reports whether a title in the title line is valid.
 func (tl TitleLine) HasValidTitle() bool {
 	return tl.title != nil
@@ -30,13 +44,50 @@ func NewTitleLine(tl string) (*TitleLine, bool) {
 		return nil, false
 	}
 
+	level := len(tl) - len(strings.TrimLeft(tl, atxHeadingChar))
 	titleStr := strings.Trim(tl, atxHeadingChar+" ")
 	title, err := NewTitle(titleStr)
 	if err != nil {
-		return &TitleLine{title: nil}, true
+		return &TitleLine{title: nil, level: level}, true
+	}
+
+	return &TitleLine{title: title, level: level}, true
+}
+
+// NewSetextTitleLine returns title line of a setext heading,
+// which is a text line followed by an underline of = or -.
+func NewSetextTitleLine(line, underline string) (*TitleLine, bool) {
+	if !isSetextText(line) || !setextUnderline.MatchString(underline) {
+		return nil, false
+	}
+
+	title, err := NewTitle(line)
+	if err != nil {
+		return nil, false
+	}
+
+	// The underline of = is level 1 and - is level 2.
+	level := 1
+	if underline[0] == '-' {
+		level = 2
+	}
+
+	return &TitleLine{title: title, level: level}, true
+}
+
+// isSetextText reports whether the line can be the text of a setext heading.
+func isSetextText(line string) bool {
+	// Text with spaces before is not recognized as well as ATX headings.
+	if line == "" || strings.HasPrefix(line, " ") {
+		return false
+	}
+
+	if isTitleLine(line) || IsFenceLine(line) || setextUnderline.MatchString(line) {
+		return false
 	}
 
-	return &TitleLine{title: title}, true
+	// List items and block quotes are not headings.
+	return !listItemMarker.MatchString(line) && !strings.HasPrefix(line, ">")
 }
 
 // isTitleLine returns if the line is title line.
@@ -62,6 +62,72 @@ func TestNewTitleLine(t *testing.T) {
 	})
 }
 
+func TestNewSetextTitleLine(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns True", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name      string
+			line      string
+			underline string
+		}{
+			{name: "equals underline", line: "title string", underline: "====="},
+			{name: "dashes underline", line: "title string", underline: "---"},
+			{name: "short underline", line: "title string", underline: "="},
+			{name: "underline with trailing spaces", line: "title string", underline: "---  "},
+			{name: "trailing spaces in title", line: "title string  ", underline: "==="},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				titleLine, ok := value.NewSetextTitleLine(tc.line, tc.underline)
+
+				require.True(t, ok)
+				assert.Equal(t, "title string", titleLine.Title().String())
+			})
+		}
+	})
+
+	t.Run("returns False", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name      string
+			line      string
+			underline string
+		}{
+			{name: "no underline", line: "title string", underline: "text"},
+			{name: "mixed underline", line: "title string", underline: "=-="},
+			{name: "underline with spaces", line: "title string", underline: "- - -"},
+			{name: "underline with spaces before", line: "title string", underline: " ---"},
+			{name: "vacant line", line: "", underline: "---"},
+			{name: "only spaces", line: "  ", underline: "---"},
+			{name: "spaces before", line: " title string", underline: "---"},
+			{name: "atx heading", line: "# title string", underline: "---"},
+			{name: "fence", line: "```", underline: "---"},
+			{name: "underline", line: "===", underline: "==="},
+			{name: "list item", line: "- item", underline: "---"},
+			{name: "ordered list item", line: "1. item", underline: "---"},
+			{name: "block quote", line: "> quote", underline: "---"},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				titleLine, ok := value.NewSetextTitleLine(tc.line, tc.underline)
+
+				require.False(t, ok)
+				assert.Nil(t, titleLine)
+			})
+		}
+	})
+}
+
 func TestTitleLineTitle(t *testing.T) {
 	t.Parallel()
 
@@ -87,3 +153,37 @@ func TestTitleLineEqualTitle(t *testing.T) {
 	assert.True(t, titleLine.EqualTitle(title))
 	assert.False(t, titleLine.EqualTitle(otherTitle))
 }
+
+func TestTitleLineLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		line      string
+		underline string
+		level     int
+	}{
+		{name: "atx level 1", line: "# title", level: 1},
+		{name: "atx level 3", line: "### title", level: 3},
+		{name: "atx blank title", line: "##", level: 2},
+		{name: "setext equals", line: "title", underline: "===", level: 1},
+		{name: "setext dashes", line: "title", underline: "---", level: 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var titleLine *value.TitleLine
+			var ok bool
+			if tc.underline == "" {
+				titleLine, ok = value.NewTitleLine(tc.line)
+			} else {
+				titleLine, ok = value.NewSetextTitleLine(tc.line, tc.underline)
+			}
+
+			require.True(t, ok)
+			assert.Equal(t, tc.level, titleLine.Level())
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package value
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	titlePathSeparator = "/"
+	escapedSeparator   = `\/`
+)
+
+// TitlePath represents a path of titles from a top-level note to a sub note.
+type TitlePath struct {
+	titles []Title
+}
+
+// String returns a title path string whose titles are separated by /.
+// The separators in titles are escaped with a backslash.
+func (tp TitlePath) String() string {
+	titles := make([]string, 0, len(tp.titles))
+	for _, t := range tp.titles {
+		titles = append(titles, strings.ReplaceAll(t.String(), titlePathSeparator, escapedSeparator))
+	}
+
+	return strings.Join(titles, titlePathSeparator)
+}
+
+// Titles returns titles in the title path.
+func (tp TitlePath) Titles() []Title {
+	return slices.Clone(tp.titles)
+}
+
+// Leaf returns the last title in the title path.
+func (tp TitlePath) Leaf() Title {
+	return tp.titles[len(tp.titles)-1]
+}
+
+// Equals reports whether the title path equals to other title path.
+func (tp TitlePath) Equals(other *TitlePath) bool {
+	return slices.Equal(tp.titles, other.titles)
+}
+
+// NewTitlePath returns a title path from a string whose titles are separated by /.
+func NewTitlePath(p string) (*TitlePath, error) {
+	var titles []Title
+
+	for _, s := range splitTitlePath(p) {
+		title, err := NewTitle(strings.ReplaceAll(s, escapedSeparator, titlePathSeparator))
+		if err != nil {
+			return nil, err
+		}
+		titles = append(titles, *title)
+	}
+
+	return &TitlePath{titles: titles}, nil
+}
+
+// NewTitlePathFromTitles returns a title path consisting of the titles.
+func NewTitlePathFromTitles(titles []Title) (*TitlePath, error) {
+	if len(titles) == 0 {
+		return nil, ErrEmptyTitle
+	}
+
+	return &TitlePath{titles: slices.Clone(titles)}, nil
+}
+
+// splitTitlePath splits the title path string at separators that are not escaped.
+func splitTitlePath(p string) []string {
+	var elems []string
+
+	start := 0
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], escapedSeparator):
+			i++
+		case strings.HasPrefix(p[i:], titlePathSeparator):
+			elems = append(elems, p[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(elems, p[start:])
+}
@@ -0,0 +1,101 @@
+package value_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yendo/fcqs/internal/value"
+)
+
+func TestNewTitlePath(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success cases", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name   string
+			path   string
+			titles []string
+			str    string
+		}{
+			{name: "single title", path: "parent", titles: []string{"parent"}, str: "parent"},
+			{name: "nested titles", path: "parent/child", titles: []string{"parent", "child"}, str: "parent/child"},
+			{name: "un-trimmed titles", path: " parent / child ", titles: []string{"parent", "child"}, str: "parent/child"},
+			{name: "escaped separator", path: `TCP\/IP/child`, titles: []string{"TCP/IP", "child"}, str: `TCP\/IP/child`},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				path, err := value.NewTitlePath(tc.path)
+
+				require.NoError(t, err)
+				titles := make([]string, 0, len(tc.titles))
+				for _, title := range path.Titles() {
+					titles = append(titles, title.String())
+				}
+				assert.Equal(t, tc.titles, titles)
+				assert.Equal(t, tc.titles[len(tc.titles)-1], path.Leaf().String())
+				assert.Equal(t, tc.str, path.String())
+			})
+		}
+	})
+
+	t.Run("fail cases", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name string
+			path string
+		}{
+			{name: "empty path", path: ""},
+			{name: "empty title", path: "parent//child"},
+			{name: "trailing separator", path: "parent/"},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				path, err := value.NewTitlePath(tc.path)
+
+				require.ErrorIs(t, err, value.ErrEmptyTitle)
+				assert.Nil(t, path)
+			})
+		}
+	})
+}
+
+func TestNewTitlePathFromTitles(t *testing.T) {
+	t.Parallel()
+
+	parent, err := value.NewTitle("parent")
+	require.NoError(t, err)
+	child, err := value.NewTitle("child")
+	require.NoError(t, err)
+
+	path, err := value.NewTitlePathFromTitles([]value.Title{*parent, *child})
+	require.NoError(t, err)
+	assert.Equal(t, "parent/child", path.String())
+
+	path, err = value.NewTitlePathFromTitles(nil)
+	require.ErrorIs(t, err, value.ErrEmptyTitle)
+	assert.Nil(t, path)
+}
+
+func TestTitlePathEquals(t *testing.T) {
+	t.Parallel()
+
+	path1, err := value.NewTitlePath("parent/child")
+	require.NoError(t, err)
+	path2, err := value.NewTitlePath("parent / child")
+	require.NoError(t, err)
+	otherPath, err := value.NewTitlePath("parent")
+	require.NoError(t, err)
+
+	assert.True(t, path1.Equals(path2))
+	assert.False(t, path1.Equals(otherPath))
+}
@@ -0,0 +1,88 @@
+package fcqs
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// rxMarkdownLink matches inline links like "[text](url)" and "[text](<url> "title")".
+var rxMarkdownLink = regexp.MustCompile(`\[([^\]]*)\]\(\s*<?([^\s()<>]+)>?(?:\s+"[^"]*")?\s*\)`)
+
+// Link represents a URL in the contents of a note.
+type Link struct {
+	// Text is the link text of Markdown or the words around the URL.
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// Links returns the URLs in the contents of a note in order of appearance.
+// A URL appearing more than once is returned at the first appearance.
+func Links(contents io.Reader) ([]Link, error) {
+	var lines []string
+
+	scanner := newLineScanner(newScanner(contents))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("seek links: %w", err)
+	}
+
+	return linesLinks(lines), nil
+}
+
+// linesLinks returns the URLs in the lines in order of appearance without duplicates.
+func linesLinks(lines []string) []Link {
+	var links []Link
+	seen := make(map[string]bool)
+
+	for _, line := range lines {
+		for _, link := range lineLinks(line) {
+			if !seen[link.URL] {
+				seen[link.URL] = true
+				links = append(links, link)
+			}
+		}
+	}
+
+	return links
+}
+
+// lineLinks returns the URLs in the line with their texts.
+// The text is the URL itself if the line has no other words.
+func lineLinks(line string) []Link {
+	urls := rxStrict().FindAllStringIndex(line, -1)
+	if urls == nil {
+		return nil
+	}
+
+	mdLinks := rxMarkdownLink.FindAllStringSubmatchIndex(line, -1)
+	words := surroundingWords(line)
+
+	links := make([]Link, 0, len(urls))
+	for _, u := range urls {
+		link := Link{Text: words, URL: line[u[0]:u[1]]}
+		for _, m := range mdLinks {
+			if m[4] <= u[0] && u[1] <= m[5] {
+				link.Text = strings.TrimSpace(line[m[2]:m[3]])
+			}
+		}
+		if link.Text == "" {
+			link.Text = link.URL
+		}
+		links = append(links, link)
+	}
+
+	return links
+}
+
+// surroundingWords returns the words in the line except URLs and the syntax of Markdown.
+func surroundingWords(line string) string {
+	line = rxMarkdownLink.ReplaceAllString(line, "$1")
+	line = rxStrict().ReplaceAllString(line, "")
+	line = strings.ReplaceAll(line, "<>", "")
+
+	return strings.Trim(strings.Join(strings.Fields(line), " "), " -*+:>#[]().,")
+}
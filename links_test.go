@@ -0,0 +1,75 @@
+package fcqs_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yendo/fcqs"
+)
+
+func TestLinks(t *testing.T) {
+	// Cannot run tests in parallel due to fcqs.SetNewScannerMock
+
+	tests := []struct {
+		name     string
+		contents string
+		expect   []fcqs.Link
+	}{
+		{
+			name:     "markdown link",
+			contents: "See [the Go site](https://go.dev \"Go\") for details.\n",
+			expect:   []fcqs.Link{{Text: "the Go site", URL: "https://go.dev"}},
+		},
+		{
+			name:     "surrounding words",
+			contents: "- Docs: <https://pkg.go.dev>.\n",
+			expect:   []fcqs.Link{{Text: "Docs", URL: "https://pkg.go.dev"}},
+		},
+		{
+			name:     "reference definition",
+			contents: "[spec]: https://spec.commonmark.org\n",
+			expect:   []fcqs.Link{{Text: "spec", URL: "https://spec.commonmark.org"}},
+		},
+		{
+			name:     "only URL",
+			contents: "https://example.com\n",
+			expect:   []fcqs.Link{{Text: "https://example.com", URL: "https://example.com"}},
+		},
+		{
+			name:     "links in order without duplicates",
+			contents: "[Go](https://go.dev) and [Rust](https://rust-lang.org)\n\nhttps://go.dev\n",
+			expect: []fcqs.Link{
+				{Text: "Go", URL: "https://go.dev"},
+				{Text: "Rust", URL: "https://rust-lang.org"},
+			},
+		},
+		{
+			name:     "no links",
+			contents: "contents\n",
+			expect:   nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			links, err := fcqs.Links(strings.NewReader(tc.contents))
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, links)
+		})
+	}
+
+	t.Run("scan error to seek links", func(t *testing.T) {
+		fcqs.SetNewScannerMock(t, ErrScanForTest)
+
+		links, err := fcqs.Links(strings.NewReader("https://example.com\n"))
+
+		require.EqualError(t, err, fmt.Sprintf("seek links: %s", ErrScanForTest))
+		assert.Nil(t, links)
+	})
+}
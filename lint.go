@@ -0,0 +1,95 @@
+package fcqs
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yendo/fcqs/internal/value"
+)
+
+// Rules of the problems found by LintNotes.
+const (
+	RuleHeadingSpace   = "heading-space"
+	RuleEmptyHeading   = "empty-heading"
+	RuleUnclosedFence  = "unclosed-fence"
+	RuleDuplicateTitle = "duplicate-title"
+)
+
+// Problem represents a mistake in the notes files found by LintNotes.
+type Problem struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// String returns the problem in the format of file:line: message (rule).
+func (p Problem) String() string {
+	return fmt.Sprintf("%s:%d: %s (%s)", p.File, p.Line, p.Message, p.Rule)
+}
+
+// LintNotes returns the problems in the notes files in order of appearance.
+// It finds # without a space before a title, fenced code blocks not closed until the end of the file,
+// title lines without titles, and titles used in the preceding files.
+func LintNotes(files []*os.File) ([]Problem, error) {
+	var problems []Problem
+	titles := make(map[string]Location)
+
+	for _, file := range files {
+		var fence *value.FenceLine
+		var fenceLine int
+		state := normal
+		seen := make(map[string]bool)
+		scanner := newLineScanner(newScanner(file))
+
+		for scanner.Scan() {
+			line := scanner.Text()
+			c := scanner.LineNumber()
+			problem := Problem{File: file.Name(), Line: c}
+
+			if state == fenced {
+				if fence.IsClosedBy(line) {
+					state = normal
+				}
+				continue
+			}
+
+			if fl, ok := value.NewFenceLine(line); ok {
+				fence, fenceLine = fl, c
+				state = fenced
+			} else if tl, _, ok := scanner.scanTitleLine(); ok {
+				if !tl.HasValidTitle() {
+					problem.Rule, problem.Message = RuleEmptyHeading, "empty heading"
+					problems = append(problems, problem)
+					continue
+				}
+
+				title := tl.Title().String()
+				if first, ok := titles[title]; !ok {
+					titles[title] = Location{File: file.Name(), Line: c}
+				} else if first.File != file.Name() && !seen[title] {
+					problem.Rule = RuleDuplicateTitle
+					problem.Message = fmt.Sprintf("duplicate title %q also in %s:%d", title, first.File, first.Line)
+					problems = append(problems, problem)
+				}
+				seen[title] = true
+			} else if strings.HasPrefix(line, "#") {
+				problem.Rule, problem.Message = RuleHeadingSpace, `no space after "#" in heading`
+				problems = append(problems, problem)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("lint notes: %w", err)
+		}
+
+		// An unclosed block hides all titles after it.
+		if state == fenced {
+			problems = append(problems, Problem{
+				File: file.Name(), Line: fenceLine, Rule: RuleUnclosedFence, Message: "unclosed fence",
+			})
+		}
+	}
+
+	return problems, nil
+}
@@ -0,0 +1,106 @@
+package fcqs_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yendo/fcqs"
+	"github.com/yendo/fcqs/test"
+)
+
+// openLintNotesFiles returns the opened notes files of the contents.
+func openLintNotesFiles(t *testing.T, contents ...string) []*os.File {
+	t.Helper()
+
+	files := make([]*os.File, 0, len(contents))
+	for _, name := range writeEditNotesFiles(t, contents...) {
+		files = append(files, openTestNotesFile(t, name))
+	}
+
+	return files
+}
+
+func TestLintNotes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		contents []string
+		expect   []string
+	}{
+		{
+			name:     "no problems",
+			contents: []string{"# title\n\n```\n#comment\n```\n", "# other\n\n    #indented\n"},
+			expect:   nil,
+		},
+		{
+			name:     "no space after #",
+			contents: []string{"# title\n##sub title\n"},
+			expect:   []string{"0:2: heading-space"},
+		},
+		{
+			name:     "empty headings",
+			contents: []string{"#\ncontents\n## \n"},
+			expect:   []string{"0:1: empty-heading", "0:3: empty-heading"},
+		},
+		{
+			name:     "unclosed fence",
+			contents: []string{"# title\n````sh\nls\n```\n\n# hidden\n", "# other\n"},
+			expect:   []string{"0:2: unclosed-fence"},
+		},
+		{
+			name:     "duplicate titles",
+			contents: []string{"# title\n# title\n", "title\n-----\n# other\n# title\n", "# other\n"},
+			expect:   []string{"1:1: duplicate-title", "2:1: duplicate-title"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			files := openLintNotesFiles(t, tc.contents...)
+
+			problems, err := fcqs.LintNotes(files)
+
+			require.NoError(t, err)
+			var actual []string
+			for _, p := range problems {
+				for i, f := range files {
+					if p.File == f.Name() {
+						actual = append(actual, fmt.Sprintf("%d:%d: %s", i, p.Line, p.Rule))
+					}
+				}
+			}
+			assert.Equal(t, tc.expect, actual)
+		})
+	}
+}
+
+func TestProblemString(t *testing.T) {
+	t.Parallel()
+
+	files := openLintNotesFiles(t, "# title\n", "# title\n")
+
+	problems, err := fcqs.LintNotes(files)
+
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Equal(t, fmt.Sprintf("%[1]s:1: duplicate title \"title\" also in %[2]s:1 (duplicate-title)", files[1].Name(), files[0].Name()),
+		problems[0].String())
+}
+
+func TestLintNotesFail(t *testing.T) {
+	// Cannot run tests in parallel due to fcqs.SetNewScannerMock
+	fcqs.SetNewScannerMock(t, ErrScanForTest)
+
+	file := openTestNotesFile(t, test.NotesFile)
+
+	problems, err := fcqs.LintNotes([]*os.File{file})
+
+	require.EqualError(t, err, fmt.Sprintf("lint notes: %s", ErrScanForTest))
+	assert.Nil(t, problems)
+}
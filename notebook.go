@@ -0,0 +1,276 @@
+package fcqs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strings"
+
+	"github.com/yendo/fcqs/internal/value"
+)
+
+// Note represents a note, which is a title line and the body until the next title line.
+// Text before the first title line is a note with an empty title at level 0.
+type Note struct {
+	// Title is empty if the title line has no title.
+	Title string `json:"title"`
+	// Path is the title path from the top-level note, which is empty if any title in the path is empty.
+	Path  string `json:"path"`
+	Level int    `json:"level"`
+	File  string `json:"file"`
+	// Line and EndLine are the line numbers of the title line and the last line of the note.
+	Line    int `json:"line"`
+	EndLine int `json:"end_line"`
+	// Body is the text following the title line.
+	Body       string      `json:"body"`
+	CodeBlocks []CodeBlock `json:"code_blocks"`
+	URLs       []Link      `json:"urls"`
+
+	heading string
+	lines   []string
+	stack   headingStack
+}
+
+// hasContents reports whether the body of the note has non-empty lines.
+func (n Note) hasContents() bool {
+	return slices.ContainsFunc(n.lines, func(line string) bool { return line != "" })
+}
+
+// hasEmptyTitle reports whether the title line of the note has no title.
+func (n Note) hasEmptyTitle() bool {
+	return n.Level > 0 && n.Title == ""
+}
+
+// bodyLine returns the line number of the i-th line of the body.
+func (n Note) bodyLine(i int) int {
+	return n.EndLine - len(n.lines) + 1 + i
+}
+
+// withBody returns the note with the body and the URLs.
+// They are set only for notes in notebooks since extracting URLs is expensive.
+func (n Note) withBody() Note {
+	var body strings.Builder
+	for _, line := range n.lines {
+		body.WriteString(line + "\n")
+	}
+	n.Body = body.String()
+	n.URLs = linesLinks(n.lines)
+
+	return n
+}
+
+// writeTo writes the title line and the body of the note to the filter line by line.
+// The text of a setext heading is written at once so that the filter removes it as a title.
+func (n Note) writeTo(f io.Writer) {
+	if n.Level > 0 {
+		fmt.Fprint(f, n.heading)
+	}
+	for _, line := range n.lines {
+		fmt.Fprint(f, line)
+	}
+}
+
+// Notebook represents the notes parsed from notes files.
+type Notebook struct {
+	notes []Note
+}
+
+// NewNotebook returns an empty notebook.
+func NewNotebook() *Notebook {
+	return &Notebook{}
+}
+
+// ParseNotebook returns the notebook of the notes in the reader.
+// The file is the name of the notes file recorded in the notes.
+func ParseNotebook(r io.Reader, file string) (*Notebook, error) {
+	nb := NewNotebook()
+	if err := nb.Parse(r, file); err != nil {
+		return nil, err
+	}
+
+	return nb, nil
+}
+
+// Parse adds the notes in the reader to the notebook.
+func (nb *Notebook) Parse(r io.Reader, file string) error {
+	parsed, err := parseNotebook(bufio.NewScanner(r), file)
+	if err != nil {
+		return fmt.Errorf("parse notes: %w", err)
+	}
+	for _, note := range parsed.notes {
+		nb.notes = append(nb.notes, note.withBody())
+	}
+
+	return nil
+}
+
+// All returns all notes in order of appearance.
+func (nb *Notebook) All() iter.Seq[Note] {
+	return slices.Values(nb.notes)
+}
+
+// Find returns the notes with the title in order of appearance.
+func (nb *Notebook) Find(title string) iter.Seq[Note] {
+	t, err := value.NewTitle(title)
+	if err != nil {
+		return func(func(Note) bool) {}
+	}
+
+	return nb.find(t)
+}
+
+// FindPath returns the notes with the title path in order of appearance.
+func (nb *Notebook) FindPath(path string) iter.Seq[Note] {
+	p, err := value.NewTitlePath(path)
+	if err != nil {
+		return func(func(Note) bool) {}
+	}
+
+	return nb.findPath(p)
+}
+
+// Note returns the first note with the title.
+func (nb *Notebook) Note(title string) (Note, bool) {
+	for note := range nb.Find(title) {
+		return note, true
+	}
+
+	return Note{}, false
+}
+
+// Titles returns the titles of the notes with contents in order of appearance without duplicates.
+// The title of text before the first title line is empty.
+// A title line without a title is regarded as contents of the note before it.
+func (nb *Notebook) Titles() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		allTitles := make(map[string]bool)
+		var title string
+
+		for _, note := range nb.notes {
+			hasContents := note.hasContents()
+			if note.hasEmptyTitle() {
+				hasContents = true
+			} else {
+				title = note.Title
+			}
+
+			if !hasContents || allTitles[title] {
+				continue
+			}
+			allTitles[title] = true
+			if !yield(title) {
+				return
+			}
+		}
+	}
+}
+
+// find returns the notes with the title.
+func (nb *Notebook) find(title *value.Title) iter.Seq[Note] {
+	return func(yield func(Note) bool) {
+		for _, note := range nb.notes {
+			if note.Title == title.String() && !yield(note) {
+				return
+			}
+		}
+	}
+}
+
+// findPath returns the notes with the title path.
+func (nb *Notebook) findPath(path *value.TitlePath) iter.Seq[Note] {
+	return func(yield func(Note) bool) {
+		for _, note := range nb.notes {
+			if note.stack.matches(path) && !yield(note) {
+				return
+			}
+		}
+	}
+}
+
+// findTree returns the notes with the title path followed by their sub notes.
+func (nb *Notebook) findTree(path *value.TitlePath) iter.Seq[Note] {
+	return func(yield func(Note) bool) {
+		scopeLevel := 0
+		for _, note := range nb.notes {
+			switch {
+			case note.stack.matches(path):
+				scopeLevel = note.Level
+			case scopeLevel == 0 || note.Level <= scopeLevel:
+				scopeLevel = 0
+				continue
+			}
+
+			if !yield(note) {
+				return
+			}
+		}
+	}
+}
+
+// parseNotebook returns the notebook of the notes file scanned by the scanner.
+func parseNotebook(s *bufio.Scanner, file string) (*Notebook, error) {
+	var notes []Note
+	var stack headingStack
+	var fence *value.FenceLine
+	note := Note{File: file, Line: 1}
+	state := normal
+
+	scanner := newLineScanner(s)
+	for scanner.Scan() {
+		line := scanner.Text()
+		c := scanner.LineNumber()
+
+		switch state {
+		case normal:
+			if fl, ok := value.NewFenceLine(line); ok {
+				fence = fl
+				state = fenced
+				note.CodeBlocks = append(note.CodeBlocks, CodeBlock{Language: fl.ID()})
+			} else if tl, text, ok := scanner.scanTitleLine(); ok {
+				notes = appendNote(notes, note)
+				stack = stack.push(tl)
+				note = newNote(tl, text, slices.Clone(stack), file, c, scanner.LineNumber())
+				continue
+			}
+
+		case fenced:
+			if fence.IsClosedBy(line) {
+				state = normal
+			} else {
+				note.CodeBlocks[len(note.CodeBlocks)-1].Code += line + "\n"
+			}
+		}
+
+		note.lines = append(note.lines, line)
+		note.EndLine = c
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Notebook{notes: appendNote(notes, note)}, nil
+}
+
+// newNote returns the note of the title line spanning from the line to the end line.
+func newNote(tl *value.TitleLine, text string, stack headingStack, file string, line, endLine int) Note {
+	note := Note{Level: tl.Level(), File: file, Line: line, EndLine: endLine, heading: text, stack: stack}
+	if tl.HasValidTitle() {
+		note.Title = tl.Title().String()
+	}
+	if path, ok := stack.path(); ok {
+		note.Path = path.String()
+	}
+
+	return note
+}
+
+// appendNote appends the note unless it is text before the first title line without lines.
+func appendNote(notes []Note, note Note) []Note {
+	if note.Level == 0 && len(note.lines) == 0 {
+		return notes
+	}
+
+	return append(notes, note)
+}
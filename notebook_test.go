@@ -0,0 +1,143 @@
+package fcqs_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yendo/fcqs"
+)
+
+const notebookContents = `preamble
+
+# parent
+
+See [Go](https://go.dev).
+
+## child
+
+` + "```sh" + `
+# not a heading
+ls
+` + "```" + `
+
+Setext
+------
+
+setext body
+
+#
+
+# parent
+
+more
+`
+
+func TestParseNotebook(t *testing.T) {
+	t.Parallel()
+
+	nb, err := fcqs.ParseNotebook(strings.NewReader(notebookContents), "notes.md")
+	require.NoError(t, err)
+
+	type note struct {
+		title   string
+		path    string
+		level   int
+		line    int
+		endLine int
+	}
+
+	var notes []note
+	for n := range nb.All() {
+		assert.Equal(t, "notes.md", n.File)
+		notes = append(notes, note{n.Title, n.Path, n.Level, n.Line, n.EndLine})
+	}
+
+	assert.Equal(t, []note{
+		{"", "", 0, 1, 2},
+		{"parent", "parent", 1, 3, 6},
+		{"child", "parent/child", 2, 7, 13},
+		{"Setext", "parent/Setext", 2, 14, 18},
+		{"", "", 1, 19, 20},
+		{"parent", "parent", 1, 21, 23},
+	}, notes)
+}
+
+func TestNotebookNote(t *testing.T) {
+	t.Parallel()
+
+	nb, err := fcqs.ParseNotebook(strings.NewReader(notebookContents), "notes.md")
+	require.NoError(t, err)
+
+	t.Run("body and URLs", func(t *testing.T) {
+		t.Parallel()
+
+		note, ok := nb.Note("parent")
+		require.True(t, ok)
+		assert.Equal(t, "\nSee [Go](https://go.dev).\n\n", note.Body)
+		assert.Equal(t, []fcqs.Link{{Text: "Go", URL: "https://go.dev"}}, note.URLs)
+		assert.Empty(t, note.CodeBlocks)
+	})
+
+	t.Run("code blocks", func(t *testing.T) {
+		t.Parallel()
+
+		note, ok := nb.Note(" child ")
+		require.True(t, ok)
+		assert.Equal(t, []fcqs.CodeBlock{{Language: "sh", Code: "# not a heading\nls\n"}}, note.CodeBlocks)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := nb.Note("not a heading")
+		assert.False(t, ok)
+
+		_, ok = nb.Note("")
+		assert.False(t, ok)
+	})
+}
+
+func TestNotebookFind(t *testing.T) {
+	t.Parallel()
+
+	nb, err := fcqs.ParseNotebook(strings.NewReader(notebookContents), "notes.md")
+	require.NoError(t, err)
+
+	var lines []int
+	for note := range nb.Find("parent") {
+		lines = append(lines, note.Line)
+	}
+	assert.Equal(t, []int{3, 21}, lines)
+
+	lines = nil
+	for note := range nb.FindPath("parent/Setext") {
+		lines = append(lines, note.Line)
+	}
+	assert.Equal(t, []int{14}, lines)
+
+	assert.Empty(t, slices.Collect(nb.FindPath("child")))
+}
+
+func TestNotebookTitles(t *testing.T) {
+	t.Parallel()
+
+	nb := fcqs.NewNotebook()
+	require.NoError(t, nb.Parse(strings.NewReader(notebookContents), "notes.md"))
+	require.NoError(t, nb.Parse(strings.NewReader("# other\ncontents\n# empty\n"), "other.md"))
+
+	assert.Equal(t, []string{"", "parent", "child", "Setext", "other"}, slices.Collect(nb.Titles()))
+	assert.Len(t, slices.Collect(nb.All()), 8)
+}
+
+func TestNotebookParseError(t *testing.T) {
+	t.Parallel()
+
+	nb, err := fcqs.ParseNotebook(iotest.ErrReader(ErrScanForTest), "notes.md")
+
+	require.EqualError(t, err, "parse notes: "+ErrScanForTest.Error())
+	assert.Nil(t, nb)
+}
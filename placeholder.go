@@ -0,0 +1,58 @@
+package fcqs
+
+import (
+	"regexp"
+	"slices"
+)
+
+// rxPlaceholder matches placeholders like "{{name}}" and "{{name:default}}".
+var rxPlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*(:[^}]*)?\}\}`)
+
+// Placeholder represents a variable to be replaced in a command line block.
+type Placeholder struct {
+	Name       string
+	Default    string
+	HasDefault bool
+}
+
+// newPlaceholder returns the placeholder of the submatches of rxPlaceholder.
+func newPlaceholder(m []string) Placeholder {
+	if m[2] == "" {
+		return Placeholder{Name: m[1]}
+	}
+
+	return Placeholder{Name: m[1], Default: m[2][1:], HasDefault: true}
+}
+
+// Placeholders returns the placeholders in the command in order of appearance.
+// A placeholder appearing more than once is returned at the first appearance.
+func Placeholders(cmd string) []Placeholder {
+	var placeholders []Placeholder
+
+	for _, m := range rxPlaceholder.FindAllStringSubmatch(cmd, -1) {
+		p := newPlaceholder(m)
+		if !slices.ContainsFunc(placeholders, func(other Placeholder) bool { return other.Name == p.Name }) {
+			placeholders = append(placeholders, p)
+		}
+	}
+
+	return placeholders
+}
+
+// ExpandPlaceholders returns the command whose placeholders are replaced with the values of the variables.
+// Placeholders without values are replaced with their default values,
+// and are left as they are if they have no default values.
+func ExpandPlaceholders(cmd string, vars map[string]string) string {
+	return rxPlaceholder.ReplaceAllStringFunc(cmd, func(s string) string {
+		p := newPlaceholder(rxPlaceholder.FindStringSubmatch(s))
+
+		if v, ok := vars[p.Name]; ok {
+			return v
+		}
+		if p.HasDefault {
+			return p.Default
+		}
+
+		return s
+	})
+}
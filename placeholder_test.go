@@ -0,0 +1,109 @@
+package fcqs_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yendo/fcqs"
+)
+
+func TestPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		cmd    string
+		expect []fcqs.Placeholder
+	}{
+		{
+			name:   "no placeholders",
+			cmd:    "ls -l",
+			expect: nil,
+		},
+		{
+			name: "placeholders",
+			cmd:  "kubectl logs {{pod}} -n {{namespace:default}}",
+			expect: []fcqs.Placeholder{
+				{Name: "pod"},
+				{Name: "namespace", Default: "default", HasDefault: true},
+			},
+		},
+		{
+			name: "duplicated placeholders",
+			cmd:  "cp {{file}} {{file}}.bak && vi {{ file }}",
+			expect: []fcqs.Placeholder{
+				{Name: "file"},
+			},
+		},
+		{
+			name: "empty default",
+			cmd:  "echo {{message:}}",
+			expect: []fcqs.Placeholder{
+				{Name: "message", HasDefault: true},
+			},
+		},
+		{
+			name:   "go template",
+			cmd:    "docker inspect -f '{{.State.Status}}' {{json .}}",
+			expect: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expect, fcqs.Placeholders(tc.cmd))
+		})
+	}
+}
+
+func TestExpandPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		cmd    string
+		vars   map[string]string
+		expect string
+	}{
+		{
+			name:   "values",
+			cmd:    "kubectl logs {{pod}} -n {{namespace:default}}",
+			vars:   map[string]string{"pod": "web-0", "namespace": "prod"},
+			expect: "kubectl logs web-0 -n prod",
+		},
+		{
+			name:   "default values",
+			cmd:    "kubectl logs {{pod}} -n {{namespace:default}}",
+			vars:   map[string]string{"pod": "web-0"},
+			expect: "kubectl logs web-0 -n default",
+		},
+		{
+			name:   "no values",
+			cmd:    "kubectl logs {{pod}} -n {{namespace:default}}",
+			vars:   nil,
+			expect: "kubectl logs {{pod}} -n default",
+		},
+		{
+			name:   "empty value and empty default",
+			cmd:    "echo {{a}}{{b:}}.",
+			vars:   map[string]string{"a": ""},
+			expect: "echo .",
+		},
+		{
+			name:   "all occurrences",
+			cmd:    "cp {{file}} {{ file }}.bak",
+			vars:   map[string]string{"file": "a.txt"},
+			expect: "cp a.txt a.txt.bak",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expect, fcqs.ExpandPlaceholders(tc.cmd, tc.vars))
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package fcqs
+
+import (
+	"bufio"
+
+	"github.com/yendo/fcqs/internal/value"
+)
+
+// lineScanner represents a scanner of text lines with one-line lookahead.
+type lineScanner struct {
+	scanner    *bufio.Scanner
+	text       string
+	next       string
+	hasNext    bool
+	lineNum    int
+	offset     int64
+	nextOffset int64
+	readBytes  int64
+}
+
+// Scan advances the scanner to the next line.
+func (s *lineScanner) Scan() bool {
+	if s.hasNext {
+		s.text, s.hasNext = s.next, false
+		s.offset = s.nextOffset
+		s.lineNum++
+
+		return true
+	}
+
+	offset := s.readBytes
+	if !s.scanner.Scan() {
+		return false
+	}
+	s.text = s.scanner.Text()
+	s.offset = offset
+	s.lineNum++
+
+	return true
+}
+
+// Peek returns the next line without advancing the scanner.
+func (s *lineScanner) Peek() (string, bool) {
+	if !s.hasNext {
+		offset := s.readBytes
+		if !s.scanner.Scan() {
+			return "", false
+		}
+		s.next, s.hasNext = s.scanner.Text(), true
+		s.nextOffset = offset
+	}
+
+	return s.next, true
+}
+
+// Text returns the current line.
+func (s *lineScanner) Text() string {
+	return s.text
+}
+
+// LineNumber returns the line number of the current line.
+func (s *lineScanner) LineNumber() int {
+	return s.lineNum
+}
+
+// Offset returns the byte offset of the current line.
+func (s *lineScanner) Offset() int64 {
+	return s.offset
+}
+
+// scanLines is bufio.ScanLines that counts the read bytes.
+func (s *lineScanner) scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+	s.readBytes += int64(advance)
+
+	return advance, token, err
+}
+
+// Err returns the first error that was encountered by the scanner.
+func (s *lineScanner) Err() error {
+	return s.scanner.Err()
+}
+
+// scanTitleLine returns the title line at the current line and its text.
+// The underline of a setext heading is consumed and included in the text.
+func (s *lineScanner) scanTitleLine() (*value.TitleLine, string, bool) {
+	line := s.Text()
+
+	if tl, ok := value.NewTitleLine(line); ok {
+		return tl, line, true
+	}
+
+	if next, ok := s.Peek(); ok {
+		if tl, ok := value.NewSetextTitleLine(line, next); ok {
+			s.Scan()
+
+			return tl, line + "\n" + next, true
+		}
+	}
+
+	return nil, line, false
+}
+
+// newLineScanner returns a line scanner.
+func newLineScanner(scanner *bufio.Scanner) *lineScanner {
+	s := &lineScanner{scanner: scanner}
+	scanner.Split(s.scanLines)
+
+	return s
+}
@@ -0,0 +1,221 @@
+package fcqs
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/yendo/fcqs/internal/value"
+)
+
+const (
+	// Parameters of BM25.
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// Weights of the fields of notes.
+	titleWeight = 3.0
+	bodyWeight  = 1.0
+	codeWeight  = 1.0
+)
+
+var ErrEmptyQuery = errors.New("empty query")
+
+// searchDoc represents a note as a document for the search.
+type searchDoc struct {
+	title string
+	order int
+	// tf has the term frequencies weighted by the fields.
+	tf     map[string]float64
+	length float64
+	score  float64
+}
+
+// add adds the terms of the text in the field of the weight.
+func (d *searchDoc) add(text string, weight float64) {
+	for _, term := range tokenize(text) {
+		d.tf[term] += weight
+		d.length += weight
+	}
+}
+
+// tokenize returns the lower case terms of the text split by non-word characters.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// WriteSearchResults writes the titles of the notes relevant to the query in order of BM25 score.
+// The titles are boosted over the bodies and the code blocks.
+func WriteSearchResults(w io.Writer, r io.Reader, query string) error {
+	return writeSearchResults(w, r, query, false)
+}
+
+// WriteHierarchicalSearchResults writes the title paths of the notes relevant to the query in order of BM25 score.
+// Each note has the contents until the next heading.
+func WriteHierarchicalSearchResults(w io.Writer, r io.Reader, query string) error {
+	return writeSearchResults(w, r, query, true)
+}
+
+// writeSearchResults writes the titles or the title paths of the notes ranked by the query.
+func writeSearchResults(w io.Writer, r io.Reader, query string, hierarchical bool) error {
+	results, err := SearchNotes(r, query, hierarchical)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		fmt.Fprintln(w, result.Title)
+	}
+
+	return nil
+}
+
+// SearchResult represents a note relevant to the query.
+type SearchResult struct {
+	Title string  `json:"title"`
+	Score float64 `json:"score"`
+}
+
+// SearchNotes returns the notes relevant to the query in order of BM25 score.
+// The titles are the title paths if hierarchical is true.
+func SearchNotes(r io.Reader, query string, hierarchical bool) ([]SearchResult, error) {
+	terms := slices.Compact(slices.Sorted(slices.Values(tokenize(query))))
+	if len(terms) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	docs, err := collectSearchDocs(r, hierarchical)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, doc := range rankSearchDocs(docs, terms) {
+		results = append(results, SearchResult{Title: doc.title, Score: doc.score})
+	}
+
+	return results, nil
+}
+
+// collectSearchDocs returns the documents of the notes.
+// Notes with the same title are merged into a document.
+func collectSearchDocs(r io.Reader, hierarchical bool) ([]*searchDoc, error) {
+	var docs []*searchDoc
+	docByTitle := make(map[string]*searchDoc)
+	var doc *searchDoc
+
+	var stack headingStack
+	var fence *value.FenceLine
+	state := normal
+	scanner := newLineScanner(bufio.NewScanner(r))
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch state {
+		case normal:
+			if fl, ok := value.NewFenceLine(line); ok {
+				fence = fl
+				state = fenced
+			} else if tl, _, ok := scanner.scanTitleLine(); ok {
+				stack = stack.push(tl)
+				doc = nil
+
+				title, ok := stack.title(hierarchical)
+				if !ok {
+					continue
+				}
+
+				doc, ok = docByTitle[title]
+				if !ok {
+					doc = &searchDoc{title: title, order: len(docs), tf: make(map[string]float64)}
+					docByTitle[title] = doc
+					docs = append(docs, doc)
+				}
+				doc.add(tl.Title().String(), titleWeight)
+				continue
+			}
+
+			if doc != nil {
+				weight := bodyWeight
+				if state == fenced {
+					weight = codeWeight
+				}
+				doc.add(line, weight)
+			}
+
+		case fenced:
+			if fence.IsClosedBy(line) {
+				state = normal
+			}
+			if doc != nil {
+				doc.add(line, codeWeight)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("search notes: %w", err)
+	}
+
+	return docs, nil
+}
+
+// rankSearchDocs returns the documents including any of the terms sorted by BM25 score.
+// Documents with the same score keep the original order.
+func rankSearchDocs(docs []*searchDoc, terms []string) []*searchDoc {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	var totalLength float64
+	for _, doc := range docs {
+		totalLength += doc.length
+	}
+	avgLength := totalLength / float64(len(docs))
+
+	var ranked []*searchDoc
+	for _, term := range terms {
+		n := 0
+		for _, doc := range docs {
+			if doc.tf[term] > 0 {
+				n++
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		idf := math.Log(1 + (float64(len(docs)-n)+0.5)/(float64(n)+0.5))
+
+		for _, doc := range docs {
+			tf := doc.tf[term]
+			if tf == 0 {
+				continue
+			}
+			if doc.score == 0 {
+				ranked = append(ranked, doc)
+			}
+			norm := 1 - bm25B
+			if avgLength > 0 {
+				norm += bm25B * doc.length / avgLength
+			}
+			doc.score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+
+	slices.SortFunc(ranked, func(a, b *searchDoc) int {
+		if c := cmp.Compare(b.score, a.score); c != 0 {
+			return c
+		}
+		return a.order - b.order
+	})
+
+	return ranked
+}
@@ -0,0 +1,94 @@
+package fcqs_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yendo/fcqs"
+	"github.com/yendo/fcqs/test"
+)
+
+func TestWriteSearchResults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		query  string
+		expect string
+	}{
+		{name: "title boosted", query: "iptables", expect: "iptables\nfirewall with docker\n"},
+		{name: "shorter note first", query: "docker", expect: "docker\nfirewall with docker\n"},
+		{name: "case insensitive", query: "DOCKER", expect: "docker\nfirewall with docker\n"},
+		{name: "code block", query: "dport", expect: "iptables\n"},
+		{name: "any terms", query: "container iptables", expect: "iptables\ndocker\nfirewall with docker\n"},
+		{name: "merged notes", query: "stopped", expect: "docker\n"},
+		{name: "no match", query: "kubernetes", expect: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			file := openTestNotesFile(t, test.SearchFile)
+
+			var buf bytes.Buffer
+			err := fcqs.WriteSearchResults(&buf, file, tc.query)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, buf.String())
+		})
+	}
+
+	t.Run("empty query", func(t *testing.T) {
+		t.Parallel()
+
+		file := openTestNotesFile(t, test.SearchFile)
+
+		var buf bytes.Buffer
+		err := fcqs.WriteSearchResults(&buf, file, " !? ")
+
+		require.ErrorIs(t, err, fcqs.ErrEmptyQuery)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("fail with scan error", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		err := fcqs.WriteSearchResults(&buf, iotest.ErrReader(ErrScanForTest), "docker")
+
+		require.EqualError(t, err, fmt.Sprintf("search notes: %s", ErrScanForTest))
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestWriteHierarchicalSearchResults(t *testing.T) {
+	t.Parallel()
+
+	file := openTestNotesFile(t, test.HierarchyFile)
+
+	var buf bytes.Buffer
+	err := fcqs.WriteHierarchicalSearchResults(&buf, file, "child")
+
+	require.NoError(t, err)
+	assert.Equal(t, "TCP\\/IP/child\nparent/child\nparent/other child\n", buf.String())
+}
+
+func TestSearchNotes(t *testing.T) {
+	t.Parallel()
+
+	file := openTestNotesFile(t, test.HierarchyFile)
+
+	results, err := fcqs.SearchNotes(file, "child", true)
+
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, "TCP\\/IP/child", results[0].Title)
+	assert.Positive(t, results[2].Score)
+	assert.GreaterOrEqual(t, results[0].Score, results[1].Score)
+	assert.GreaterOrEqual(t, results[1].Score, results[2].Score)
+}
@@ -7,39 +7,82 @@
 # FCQS_OPEN_KEY="ctrl-o"
 # FCQS_EDIT_KEY="ctrl-e"
 # FCQS_BASH_BIND_KEY="\C-o"
+# FCQS_BASH_CAPTURE_KEY="\C-xc"
 # FCQS_COPY_COMMAND="xclip -selection c"
 # FCQS_COPY_WITH_TITLE=true
 # FCQS_OPEN_COMMAND="open"
+# FCQS_HIERARCHICAL=false
+# FCQS_FINDER="fzf" or "builtin"
+# FCQS_SORT="file" or "frecency"
+# FCQS_PROMPT_VARS=false
 
 FCQS_EDITOR=${FCQS_EDITOR:-default}
 FCQS_COPY_KEY=${FCQS_COPY_KEY:-ctrl-y}
 FCQS_OPEN_KEY=${FCQS_OPEN_KEY:-ctrl-o}
 FCQS_EDIT_KEY=${FCQS_EDIT_KEY:-ctrl-e}
 FCQS_BASH_BIND_KEY=${FCQS_BASH_BIND_KEY:-"\C-o"}
+FCQS_BASH_CAPTURE_KEY=${FCQS_BASH_CAPTURE_KEY:-"\C-xc"}
 FCQS_COPY_COMMAND=${FCQS_COPY_COMMAND:-"xclip -selection c"}
 FCQS_COPY_WITH_TITLE=${FCQS_COPY_WITH_TITLE:-true}
-FCQS_OPEN_COMMAND=${FCQS_BROWSE_COMMAND:-"open"}
+FCQS_OPEN_COMMAND=${FCQS_OPEN_COMMAND:-"open"}
+FCQS_HIERARCHICAL=${FCQS_HIERARCHICAL:-false}
+FCQS_SORT=${FCQS_SORT:-file}
+FCQS_PROMPT_VARS=${FCQS_PROMPT_VARS:-false}
+command -v fzf >/dev/null && FCQS_FINDER=${FCQS_FINDER:-fzf} || FCQS_FINDER=${FCQS_FINDER:-builtin}
 
 FCQS_EDIT_COMMAND_DEFAULT="awk '{printf \"+%s %s\n\",\$2,\$1}' | xargs -o ${VISUAL} > /dev/tty"
 FCQS_EDIT_COMMAND_VSCODE="awk '{printf \"%s:%s\n\",\$1,\$2}' | xargs -o code -g"
-[ "${FCQS_EDITOR}" = "vscode" ] && FCQS_EDIT_COMMAND=${FCQS_EDIT_COMMAND_VSCODE} || FCQS_EDIT_COMMAND=${FCQS_EDIT_COMMAND_DEFAULT}
+[ "${FCQS_EDITOR}" = "vscode" ] && FCQS_EDIT_COMMAND=${FCQS_EDIT_COMMAND:-${FCQS_EDIT_COMMAND_VSCODE}} || FCQS_EDIT_COMMAND=${FCQS_EDIT_COMMAND:-${FCQS_EDIT_COMMAND_DEFAULT}}
 
 [ "${FCQS_COPY_WITH_TITLE}" = true ] && FCQS_COPY_COMMAND_FLAG="" || FCQS_COPY_COMMAND_FLAG="-t"
+[ "${FCQS_PROMPT_VARS}" = true ] && FCQS_CMD_FLAGS="-c --prompt" || FCQS_CMD_FLAGS="-c"
+[ "${FCQS_HIERARCHICAL}" = true ] && FCQS_CLI="fcqs-cli --format text --hierarchical" || FCQS_CLI="fcqs-cli --format text"
+FCQS_URL_PICKER="fzf --delimiter '\t' --with-nth 2.. --select-1 --exit-0 | cut -f3"
+
+_fcqs_select_command() {
+  local title=$1
+  if [ "$(${FCQS_CLI} --shell bash --list-commands -- "$title" | wc -l)" -le 1 ]; then
+    echo 1
+  elif [ "${FCQS_FINDER}" = builtin ]; then
+    ${FCQS_CLI} --shell bash --select -- "$title"
+  else
+    ${FCQS_CLI} --shell bash --list-commands -- "$title" |
+      fzf --delimiter '\t' --with-nth 2.. --preview "${FCQS_CLI} --shell bash --command-index {1} -- $(printf %q "$title")" |
+      cut -f1
+  fi
+}
 
 fcqs() {
   local title
-  title=$(fcqs-cli |
-    fzf --preview "fcqs-cli {}" \
-      --bind "${FCQS_COPY_KEY}:execute-silent(fcqs-cli ${FCQS_COPY_COMMAND_FLAG} {} | ${FCQS_COPY_COMMAND}),${FCQS_OPEN_KEY}:execute-silent(fcqs-cli -u {} | xargs ${FCQS_OPEN_COMMAND}),${FCQS_EDIT_KEY}:execute-silent(fcqs-cli -l {} | ${FCQS_EDIT_COMMAND})+abort")
+  if [ "${FCQS_FINDER}" = builtin ]; then
+    title=$(FCQS_COPY_KEY=${FCQS_COPY_KEY} FCQS_OPEN_KEY=${FCQS_OPEN_KEY} FCQS_EDIT_KEY=${FCQS_EDIT_KEY} \
+      FCQS_COPY_COMMAND=${FCQS_COPY_COMMAND} FCQS_COPY_WITH_TITLE=${FCQS_COPY_WITH_TITLE} \
+      FCQS_OPEN_COMMAND=${FCQS_OPEN_COMMAND} FCQS_EDIT_COMMAND=${FCQS_EDIT_COMMAND} \
+      ${FCQS_CLI} --sort "${FCQS_SORT}" --select)
+  else
+    title=$(${FCQS_CLI} --sort "${FCQS_SORT}" --source |
+      fzf --delimiter '\t' --nth 2.. --preview "${FCQS_CLI} -- {2..}" \
+        --bind "${FCQS_COPY_KEY}:execute-silent(${FCQS_CLI} ${FCQS_COPY_COMMAND_FLAG} -- {2..} | ${FCQS_COPY_COMMAND}),${FCQS_OPEN_KEY}:execute(${FCQS_CLI} --urls -- {2..} | ${FCQS_URL_PICKER} | xargs ${FCQS_OPEN_COMMAND}),${FCQS_EDIT_KEY}:execute-silent(${FCQS_CLI} -l -- {2..} | ${FCQS_EDIT_COMMAND})+abort" |
+      cut -f2-)
+  fi
 
   if [ -n "$title" ]; then
-    fcqs-cli "$title"
+    ${FCQS_CLI} --record -- "$title"
+    ${FCQS_CLI} -- "$title"
 
-    local command
-    command=$(fcqs-cli -c "$title")
+    local index command
+    index=$(_fcqs_select_command "$title")
+    [ -n "$index" ] && command=$(${FCQS_CLI} ${FCQS_CMD_FLAGS} --shell bash --command-index "$index" -- "$title")
     READLINE_LINE="${READLINE_LINE:0:$READLINE_POINT}${command}${READLINE_LINE:$READLINE_POINT}"
     READLINE_POINT=$((READLINE_POINT + ${#command}))
   fi
 }
 
+fcqs-capture() {
+  local command=${READLINE_LINE}
+  [ -n "$command" ] || command=$(fc -ln -1 | sed 's/^[[:space:]]*//')
+  [ -n "$command" ] && printf '%s\n' "$command" | fcqs-cli --format text capture --shell bash
+}
+
 eval "bind -x '\"${FCQS_BASH_BIND_KEY}\":fcqs'"
+eval "bind -x '\"${FCQS_BASH_CAPTURE_KEY}\":fcqs-capture'"
@@ -0,0 +1,97 @@
+# fcqs
+
+# You can customize settings:
+#
+# FCQS_EDITOR="default" or "vscode"
+# FCQS_COPY_KEY="ctrl-y"
+# FCQS_OPEN_KEY="ctrl-o"
+# FCQS_EDIT_KEY="ctrl-e"
+# FCQS_FISH_BIND_KEY="\co"
+# FCQS_FISH_CAPTURE_KEY="\cxc"
+# FCQS_COPY_COMMAND="xclip -selection c"
+# FCQS_COPY_WITH_TITLE=true
+# FCQS_OPEN_COMMAND="open"
+# FCQS_HIERARCHICAL=false
+# FCQS_FINDER="fzf" or "builtin"
+# FCQS_SORT="file" or "frecency"
+# FCQS_PROMPT_VARS=false
+
+set -q FCQS_EDITOR; or set -g FCQS_EDITOR default
+set -q FCQS_COPY_KEY; or set -g FCQS_COPY_KEY ctrl-y
+set -q FCQS_OPEN_KEY; or set -g FCQS_OPEN_KEY ctrl-o
+set -q FCQS_EDIT_KEY; or set -g FCQS_EDIT_KEY ctrl-e
+set -q FCQS_FISH_BIND_KEY; or set -g FCQS_FISH_BIND_KEY \co
+set -q FCQS_FISH_CAPTURE_KEY; or set -g FCQS_FISH_CAPTURE_KEY \cxc
+set -q FCQS_COPY_COMMAND; or set -g FCQS_COPY_COMMAND "xclip -selection c"
+set -q FCQS_COPY_WITH_TITLE; or set -g FCQS_COPY_WITH_TITLE true
+set -q FCQS_OPEN_COMMAND; or set -g FCQS_OPEN_COMMAND open
+set -q FCQS_HIERARCHICAL; or set -g FCQS_HIERARCHICAL false
+set -q FCQS_SORT; or set -g FCQS_SORT file
+set -q FCQS_PROMPT_VARS; or set -g FCQS_PROMPT_VARS false
+if not set -q FCQS_FINDER
+    type -q fzf; and set -g FCQS_FINDER fzf; or set -g FCQS_FINDER builtin
+end
+
+set -g FCQS_EDIT_COMMAND_DEFAULT "awk '{printf \"+%s %s\n\",\$2,\$1}' | xargs -o $VISUAL > /dev/tty"
+set -g FCQS_EDIT_COMMAND_VSCODE "awk '{printf \"%s:%s\n\",\$1,\$2}' | xargs -o code -g"
+if not set -q FCQS_EDIT_COMMAND
+    test "$FCQS_EDITOR" = vscode; and set -g FCQS_EDIT_COMMAND $FCQS_EDIT_COMMAND_VSCODE; or set -g FCQS_EDIT_COMMAND $FCQS_EDIT_COMMAND_DEFAULT
+end
+
+test "$FCQS_COPY_WITH_TITLE" = true; and set -g FCQS_COPY_COMMAND_FLAG ""; or set -g FCQS_COPY_COMMAND_FLAG -t
+test "$FCQS_PROMPT_VARS" = true; and set -g FCQS_CMD_FLAGS -c --prompt; or set -g FCQS_CMD_FLAGS -c
+test "$FCQS_HIERARCHICAL" = true; and set -g FCQS_CLI fcqs-cli --format text --hierarchical; or set -g FCQS_CLI fcqs-cli --format text
+set -g FCQS_URL_PICKER "fzf --delimiter '\t' --with-nth 2.. --select-1 --exit-0 | cut -f3"
+
+function _fcqs_select_command
+    set -l title $argv[1]
+    if test ($FCQS_CLI --shell fish --list-commands -- $title | count) -le 1
+        echo 1
+    else if test "$FCQS_FINDER" = builtin
+        $FCQS_CLI --shell fish --select -- $title
+    else
+        $FCQS_CLI --shell fish --list-commands -- $title |
+            fzf --delimiter \t --with-nth 2.. --preview "$FCQS_CLI --shell fish --command-index {1} -- "(string escape -- $title) |
+            cut -f1
+    end
+end
+
+function fcqs
+    set -l title
+    if test "$FCQS_FINDER" = builtin
+        set title (env FCQS_COPY_KEY=$FCQS_COPY_KEY FCQS_OPEN_KEY=$FCQS_OPEN_KEY FCQS_EDIT_KEY=$FCQS_EDIT_KEY \
+            FCQS_COPY_COMMAND=$FCQS_COPY_COMMAND FCQS_COPY_WITH_TITLE=$FCQS_COPY_WITH_TITLE \
+            FCQS_OPEN_COMMAND=$FCQS_OPEN_COMMAND FCQS_EDIT_COMMAND=$FCQS_EDIT_COMMAND \
+            $FCQS_CLI --sort $FCQS_SORT --select)
+    else
+        set title ($FCQS_CLI --sort $FCQS_SORT --source |
+            fzf --delimiter \t --nth 2.. --preview "$FCQS_CLI -- {2..}" \
+                --bind "$FCQS_COPY_KEY:execute-silent($FCQS_CLI $FCQS_COPY_COMMAND_FLAG -- {2..} | $FCQS_COPY_COMMAND),$FCQS_OPEN_KEY:execute($FCQS_CLI --urls -- {2..} | $FCQS_URL_PICKER | xargs $FCQS_OPEN_COMMAND),$FCQS_EDIT_KEY:execute-silent($FCQS_CLI -l -- {2..} | $FCQS_EDIT_COMMAND)+abort" |
+            cut -f2-)
+    end
+
+    if test -n "$title"
+        $FCQS_CLI --record -- $title
+        $FCQS_CLI -- $title
+
+        set -l index (_fcqs_select_command $title)
+        if test -n "$index"
+            commandline -i -- ($FCQS_CLI $FCQS_CMD_FLAGS --shell fish --command-index $index -- $title | string collect)
+        end
+    end
+
+    commandline -f repaint
+end
+
+function fcqs-capture
+    set -l command (commandline | string collect)
+    test -n "$command"; or set command $history[1]
+    if test -n "$command"
+        printf '%s\n' $command | fcqs-cli --format text capture --shell fish
+    end
+
+    commandline -f repaint
+end
+
+bind $FCQS_FISH_BIND_KEY fcqs
+bind $FCQS_FISH_CAPTURE_KEY fcqs-capture
@@ -1,15 +1,112 @@
 package fcqs
 
 import (
-	_ "embed"
+	"embed"
+	"errors"
 	"fmt"
 	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 )
 
-//go:embed shell.bash
-var bashTemplate string
+var ErrUnsupportedShell = errors.New("unsupported shell")
+
+// Shells are the shells which have integration scripts.
+var Shells = []string{"bash", "zsh", "fish", "pwsh"}
+
+// shellScriptFiles maps the shells to their integration scripts.
+var shellScriptFiles = map[string]string{
+	"bash": "shell.bash",
+	"zsh":  "shell.zsh",
+	"fish": "shell.fish",
+	"pwsh": "shell.ps1",
+}
+
+//go:embed shell.bash shell.zsh shell.fish shell.ps1
+var shellTemplates embed.FS
+
+// WriteShellScript writes the script to set up fcqs for the shell.
+func WriteShellScript(w io.Writer, shell string) error {
+	file, ok := shellScriptFiles[shell]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedShell, shell)
+	}
+
+	script, err := shellTemplates.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("shell script: %w", err)
+	}
+	fmt.Fprint(w, string(script))
+
+	return nil
+}
 
 // WriteBashScript writes bash script to set up fcqs.
 func WriteBashScript(w io.Writer) {
-	fmt.Fprint(w, bashTemplate)
+	WriteShellScript(w, "bash") //nolint:errcheck
+}
+
+// shellAssignments maps the shells to the formats assigning a default value to a variable.
+var shellAssignments = map[string]string{
+	"bash": "[ -n \"${%[1]s}\" ] || %[1]s=%[2]s\n",
+	"zsh":  "[[ -n \"${%[1]s}\" ]] || %[1]s=%[2]s\n",
+	"fish": "set -q %[1]s; or set -g %[1]s %[2]s\n",
+	"pwsh": "if (-not $env:%[1]s) { $env:%[1]s = %[2]s }\n",
+}
+
+// shellExports maps the shells to the formats exporting an environment variable.
+var shellExports = map[string]string{
+	"bash": "export %s=%s\n",
+	"zsh":  "export %s=%s\n",
+	"fish": "set -gx %s %s\n",
+	"pwsh": "$env:%s = %s\n",
+}
+
+// WriteShellConfig writes the settings in the config file as defaults of the integration script for the shell.
+// Variables set before the script take precedence over them.
+// The config file is exported if it is not the default one.
+func WriteShellConfig(w io.Writer, shell string, cfg *Config) error {
+	assignment, ok := shellAssignments[shell]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedShell, shell)
+	}
+
+	if cfg.file != "" && cfg.file != ConfigFile() {
+		file, err := filepath.Abs(cfg.file)
+		if err != nil {
+			return fmt.Errorf("config file: %w", err)
+		}
+		fmt.Fprintf(w, shellExports[shell], "FCQS_CONFIG", shellQuote(shell, file))
+	}
+
+	for _, s := range cfg.settings() {
+		// Bind keys are only for their own shells.
+		if bindShell, _, _ := strings.Cut(s.key[1], "_"); s.key[0] == "keys" && slices.Contains(Shells, bindShell) && bindShell != shell {
+			continue
+		}
+		if cfg.isDefined(s.key...) {
+			fmt.Fprintf(w, assignment, s.env, shellQuote(shell, *s.value))
+		}
+	}
+	if cfg.isDefined("output", "hierarchical") {
+		fmt.Fprintf(w, assignment, "FCQS_HIERARCHICAL", shellQuote(shell, strconv.FormatBool(cfg.Output.Hierarchical)))
+	}
+
+	return nil
+}
+
+// shellQuote returns the string quoted with single quotes for the shell.
+func shellQuote(shell, s string) string {
+	switch shell {
+	case "fish":
+		s = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+	case "pwsh":
+		s = strings.ReplaceAll(s, `'`, `''`)
+	default:
+		s = strings.ReplaceAll(s, `'`, `'\''`)
+	}
+
+	return "'" + s + "'"
 }
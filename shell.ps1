@@ -0,0 +1,112 @@
+# fcqs
+
+# You can customize settings:
+#
+# $env:FCQS_EDITOR = "default" or "vscode"
+# $env:FCQS_COPY_KEY = "ctrl-y"
+# $env:FCQS_OPEN_KEY = "ctrl-o"
+# $env:FCQS_EDIT_KEY = "ctrl-e"
+# $env:FCQS_PWSH_BIND_KEY = "Ctrl+o"
+# $env:FCQS_PWSH_CAPTURE_KEY = "Ctrl+x,c"
+# $env:FCQS_COPY_COMMAND = "xclip -selection c"
+# $env:FCQS_COPY_WITH_TITLE = "true"
+# $env:FCQS_OPEN_COMMAND = "open"
+# $env:FCQS_HIERARCHICAL = "false"
+# $env:FCQS_FINDER = "fzf" or "builtin"
+# $env:FCQS_SORT = "file" or "frecency"
+# $env:FCQS_PROMPT_VARS = "false"
+
+if (-not $env:FCQS_EDITOR) { $env:FCQS_EDITOR = 'default' }
+if (-not $env:FCQS_COPY_KEY) { $env:FCQS_COPY_KEY = 'ctrl-y' }
+if (-not $env:FCQS_OPEN_KEY) { $env:FCQS_OPEN_KEY = 'ctrl-o' }
+if (-not $env:FCQS_EDIT_KEY) { $env:FCQS_EDIT_KEY = 'ctrl-e' }
+if (-not $env:FCQS_PWSH_BIND_KEY) { $env:FCQS_PWSH_BIND_KEY = 'Ctrl+o' }
+if (-not $env:FCQS_PWSH_CAPTURE_KEY) { $env:FCQS_PWSH_CAPTURE_KEY = 'Ctrl+x,c' }
+if (-not $env:FCQS_COPY_COMMAND) { $env:FCQS_COPY_COMMAND = 'xclip -selection c' }
+if (-not $env:FCQS_COPY_WITH_TITLE) { $env:FCQS_COPY_WITH_TITLE = 'true' }
+if (-not $env:FCQS_OPEN_COMMAND) { $env:FCQS_OPEN_COMMAND = 'open' }
+if (-not $env:FCQS_HIERARCHICAL) { $env:FCQS_HIERARCHICAL = 'false' }
+if (-not $env:FCQS_SORT) { $env:FCQS_SORT = 'file' }
+if (-not $env:FCQS_PROMPT_VARS) { $env:FCQS_PROMPT_VARS = 'false' }
+if (-not $env:FCQS_FINDER) {
+  if (Get-Command fzf -ErrorAction SilentlyContinue) { $env:FCQS_FINDER = 'fzf' } else { $env:FCQS_FINDER = 'builtin' }
+}
+
+if (-not $env:FCQS_EDIT_COMMAND) {
+  if ($env:FCQS_EDITOR -eq 'vscode') {
+    $env:FCQS_EDIT_COMMAND = 'awk ''{printf "%s:%s\n",$1,$2}'' | xargs -o code -g'
+  } else {
+    $env:FCQS_EDIT_COMMAND = 'awk ''{printf "+%s %s\n",$2,$1}'' | xargs -o ' + $env:VISUAL + ' > /dev/tty'
+  }
+}
+
+# Commands in fzf are executed by sh since they are written for POSIX shells.
+$script:FcqsCliArgs = @('--format', 'text')
+if ($env:FCQS_HIERARCHICAL -eq 'true') { $script:FcqsCliArgs += '--hierarchical' }
+$script:FcqsCli = (@('fcqs-cli') + $script:FcqsCliArgs) -join ' '
+$script:FcqsCopyCommandFlag = if ($env:FCQS_COPY_WITH_TITLE -eq 'true') { '' } else { '-t' }
+$script:FcqsCmdFlags = if ($env:FCQS_PROMPT_VARS -eq 'true') { @('-c', '--prompt') } else { @('-c') }
+$script:FcqsUrlPicker = 'fzf --delimiter ''\t'' --with-nth 2.. --select-1 --exit-0 | cut -f3'
+
+function Invoke-FcqsCli {
+  & fcqs-cli @script:FcqsCliArgs @args
+}
+
+function ConvertTo-FcqsShellQuoted([string]$Text) {
+  "'" + ($Text -replace "'", "'\''") + "'"
+}
+
+function Select-FcqsTitle {
+  if ($env:FCQS_FINDER -eq 'builtin') {
+    Invoke-FcqsCli --sort $env:FCQS_SORT --select
+  } else {
+    $bind = "$($env:FCQS_COPY_KEY):execute-silent($script:FcqsCli $script:FcqsCopyCommandFlag -- {2..} | $($env:FCQS_COPY_COMMAND))," +
+      "$($env:FCQS_OPEN_KEY):execute($script:FcqsCli --urls -- {2..} | $script:FcqsUrlPicker | xargs $($env:FCQS_OPEN_COMMAND))," +
+      "$($env:FCQS_EDIT_KEY):execute-silent($script:FcqsCli -l -- {2..} | $($env:FCQS_EDIT_COMMAND))+abort"
+    Invoke-FcqsCli --sort $env:FCQS_SORT --source |
+      fzf --with-shell 'sh -c' --delimiter "`t" --nth '2..' --preview "$script:FcqsCli -- {2..}" --bind $bind |
+      ForEach-Object { ($_ -split "`t", 2)[1] }
+  }
+}
+
+function Select-FcqsCommand([string]$Title) {
+  $labels = @(Invoke-FcqsCli --shell pwsh --list-commands '--' $Title)
+  if ($labels.Count -le 1) {
+    1
+  } elseif ($env:FCQS_FINDER -eq 'builtin') {
+    Invoke-FcqsCli --shell pwsh --select '--' $Title
+  } else {
+    $preview = "$script:FcqsCli --shell pwsh --command-index {1} -- $(ConvertTo-FcqsShellQuoted $Title)"
+    $labels | fzf --with-shell 'sh -c' --delimiter "`t" --with-nth '2..' --preview $preview | ForEach-Object { ($_ -split "`t")[0] }
+  }
+}
+
+Set-PSReadLineKeyHandler -Chord $env:FCQS_PWSH_BIND_KEY -BriefDescription fcqs -ScriptBlock {
+  $title = Select-FcqsTitle
+
+  if ($title) {
+    Invoke-FcqsCli --record '--' $title
+    Invoke-FcqsCli '--' $title | Out-Host
+
+    $index = Select-FcqsCommand $title
+    if ($index) {
+      $command = @(Invoke-FcqsCli @script:FcqsCmdFlags --shell pwsh --command-index $index '--' $title) -join "`n"
+      [Microsoft.PowerShell.PSConsoleReadLine]::Insert($command)
+    }
+  }
+
+  [Microsoft.PowerShell.PSConsoleReadLine]::InvokePrompt()
+}
+
+Set-PSReadLineKeyHandler -Chord $env:FCQS_PWSH_CAPTURE_KEY -BriefDescription fcqs-capture -ScriptBlock {
+  $line = $null
+  $cursor = $null
+  [Microsoft.PowerShell.PSConsoleReadLine]::GetBufferState([ref]$line, [ref]$cursor)
+  if (-not $line) { $line = (Get-History -Count 1).CommandLine }
+
+  if ($line) {
+    $line | fcqs-cli --format text capture --shell pwsh | Out-Host
+  }
+
+  [Microsoft.PowerShell.PSConsoleReadLine]::InvokePrompt()
+}
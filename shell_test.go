@@ -23,3 +23,44 @@ func TestWriteBashScript(t *testing.T) {
 
 	assert.Equal(t, expected, buf.String())
 }
+
+func TestWriteShellScript(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		shell    string
+		fileName string
+	}{
+		{shell: "bash", fileName: "shell.bash"},
+		{shell: "zsh", fileName: "shell.zsh"},
+		{shell: "fish", fileName: "shell.fish"},
+		{shell: "pwsh", fileName: "shell.ps1"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.shell, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := os.ReadFile(tc.fileName)
+			require.NoError(t, err)
+			expected := string(data)
+
+			var buf bytes.Buffer
+			err = fcqs.WriteShellScript(&buf, tc.shell)
+
+			require.NoError(t, err)
+			assert.Equal(t, expected, buf.String())
+		})
+	}
+
+	t.Run("unsupported shell", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		err := fcqs.WriteShellScript(&buf, "csh")
+
+		require.ErrorIs(t, err, fcqs.ErrUnsupportedShell)
+		require.EqualError(t, err, "unsupported shell: csh")
+		assert.Empty(t, buf.String())
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,6 +15,28 @@ import (
 
 const defaultNotesFile = "fcnotes.md"
 
+func TestMain(m *testing.M) {
+	// Keep the index cache of tests away from the user cache directory.
+	cacheDir, err := os.MkdirTemp("", "fcqs-cache")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CACHE_HOME", cacheDir)
+
+	// Keep the config file of the user away from tests.
+	configDir, err := os.MkdirTemp("", "fcqs-config")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", configDir)
+	os.Unsetenv("FCQS_CONFIG")
+
+	code := m.Run()
+	os.RemoveAll(cacheDir)
+	os.RemoveAll(configDir)
+	os.Exit(code)
+}
+
 type testCmd struct {
 	cmd    *exec.Cmd
 	stdout bytes.Buffer
@@ -83,6 +106,11 @@ func TestCmdSuccess(t *testing.T) {
 			options: []string{"-l", ""},
 			stdout:  "",
 		},
+		{
+			title:   "with grep flag",
+			options: []string{"--grep", "ls -l | nl"},
+			stdout:  "command-line\t84\tls -l | nl\nmore command-line blocks\t96\tls -l | nl\n",
+		},
 		{
 			title:   "without args",
 			options: []string{},
@@ -282,21 +310,77 @@ func TestDefaultNoteExists(t *testing.T) {
 	assert.Empty(t, cmd.stderr.String())
 }
 
-func TestBashScript(t *testing.T) {
+func TestShellScripts(t *testing.T) {
 	t.Parallel()
 
-	// Arrange
-	fileName := "../shell.bash"
-	data, err := os.ReadFile(fileName)
+	tests := []struct {
+		shell    string
+		fileName string
+	}{
+		{shell: "bash", fileName: "../shell.bash"},
+		{shell: "zsh", fileName: "../shell.zsh"},
+		{shell: "fish", fileName: "../shell.fish"},
+		{shell: "pwsh", fileName: "../shell.ps1"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.shell, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			data, err := os.ReadFile(tc.fileName)
+			require.NoError(t, err)
+			expected := string(data)
+
+			// Act
+			cmd := newTestCmd("--" + tc.shell)
+			err = cmd.run()
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, expected, cmd.stdout.String())
+			assert.Empty(t, cmd.stderr.String())
+		})
+	}
+}
+
+func TestShellScriptWithJSONConfig(t *testing.T) {
+	t.Setenv("FCQS_NOTES_FILE", NotesFile)
+	t.Setenv("FCQS_NOTES_FILES", "")
+
+	// The integration script must get text even if the config file makes JSON the default.
+	file := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(file, []byte("[output]\nformat = \"json\"\n"), 0o600))
+	t.Setenv("FCQS_CONFIG", file)
+
+	dir, err := filepath.Abs(".")
 	require.NoError(t, err)
-	expected := string(data)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
 
-	// Act
-	cmd := newTestCmd("--bash")
-	err = cmd.run()
+	cmd := exec.Command("bash", "-c", `source ../shell.bash 2> /dev/null; ${FCQS_CLI} --sort file --source | head -n 1; ${FCQS_CLI} -c -- "command-line"`)
+	cmd.Env = append(os.Environ(), "GOCOVERDIR=../coverdir")
+	out, err := cmd.Output()
 
-	// Assert
 	require.NoError(t, err)
-	assert.Equal(t, expected, cmd.stdout.String())
-	assert.Empty(t, cmd.stderr.String())
+	assert.Equal(t, "global\ttitle\nls -l | nl\n", string(out))
+}
+
+func TestCaptureAndRetrieve(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "notes.md")
+	t.Setenv("FCQS_NOTES_FILE", file)
+	t.Setenv("FCQS_NOTES_FILES", "")
+
+	capture := newTestCmd("capture", "--shell", "zsh", "--title", "disk usage")
+	capture.cmd.Stdin = strings.NewReader("du -sh .\n")
+	require.NoError(t, capture.run())
+	assert.Empty(t, capture.stderr.String())
+
+	// A command captured in zsh is retrievable from bash as well.
+	for _, shell := range []string{"zsh", "bash"} {
+		cmd := newTestCmd("-c", "--shell", shell, "disk usage")
+		err := cmd.run()
+
+		require.NoError(t, err)
+		assert.Equal(t, "du -sh .\n", cmd.stdout.String(), shell)
+	}
 }
@@ -13,6 +13,9 @@ const (
 	shellBlockFile    = "testdata/test_shellblock.md"
 	locationFile      = "testdata/test_location.md"
 	locationExtraFile = "testdata/test_location_extra.md"
+	hierarchyFile     = "testdata/test_hierarchy.md"
+	grepFile          = "testdata/test_grep.md"
+	searchFile        = "testdata/test_search.md"
 )
 
 var (
@@ -20,6 +23,9 @@ var (
 	ShellBlockFile    = fullPath(shellBlockFile)
 	LocationFile      = fullPath(locationFile)
 	LocationExtraFile = fullPath(locationExtraFile)
+	HierarchyFile     = fullPath(hierarchyFile)
+	GrepFile          = fullPath(grepFile)
+	SearchFile        = fullPath(searchFile)
 )
 
 // MultiFiles returns file names concatenated with PathListSeparator for FCQS_NOTES_FILE.
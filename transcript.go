@@ -0,0 +1,46 @@
+package fcqs
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// rxPrompt matches prompts like "$ ", "# ", "% ", "PS> " and "PS C:\> ".
+	rxPrompt = regexp.MustCompile(`^\s*(?:[$#%]|PS(?: [^>]*)?>)(?: |$)`)
+	// rxContinuation matches continuation prompts like "> ".
+	rxContinuation = regexp.MustCompile(`^\s*>(?: |$)`)
+)
+
+// transcriptCommand returns the commands in the console transcript.
+// Only the lines with prompts and their continuation lines are taken, and the output lines are dropped.
+// The transcript is returned as it is if it has no prompts.
+func transcriptCommand(transcript string) string {
+	var cmd strings.Builder
+	var hasPrompt, inCommand, continued bool
+
+	for _, line := range strings.Split(strings.TrimSuffix(transcript, "\n"), "\n") {
+		switch {
+		case continued || inCommand && rxContinuation.MatchString(line):
+			line = rxContinuation.ReplaceAllString(line, "")
+		case rxPrompt.MatchString(line):
+			line = rxPrompt.ReplaceAllString(line, "")
+			hasPrompt, inCommand = true, line != ""
+			if !inCommand {
+				continue
+			}
+		default:
+			inCommand, continued = false, false
+			continue
+		}
+
+		cmd.WriteString(line + "\n")
+		continued = strings.HasSuffix(line, `\`)
+	}
+
+	if !hasPrompt {
+		return transcript
+	}
+
+	return cmd.String()
+}
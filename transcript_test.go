@@ -0,0 +1,78 @@
+package fcqs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yendo/fcqs"
+)
+
+func TestCmdLineBlocksOfTranscript(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		contents string
+		expect   string
+	}{
+		{
+			name:     "drop output lines",
+			contents: "```console\n$ date\nSat Oct 17 10:00:00 UTC 2026\n$ whoami\nuser\n```\n",
+			expect:   "date\nwhoami\n",
+		},
+		{
+			name:     "root prompt",
+			contents: "```console\n# apt update\nReading package lists... Done\n```\n",
+			expect:   "apt update\n",
+		},
+		{
+			name:     "zsh prompt",
+			contents: "```shellsession\n% ls\nfile\n```\n",
+			expect:   "ls\n",
+		},
+		{
+			name:     "powershell prompt",
+			contents: "```console\nPS> Get-Date\nPS C:\\Users> Get-Location\n```\n",
+			expect:   "Get-Date\nGet-Location\n",
+		},
+		{
+			name:     "backslash continuation",
+			contents: "```console\n$ docker run \\\n  -it ubuntu\nroot@host:/#\n```\n",
+			expect:   "docker run \\\n  -it ubuntu\n",
+		},
+		{
+			name:     "continuation prompt",
+			contents: "```console\n$ for i in 1 2; do\n> echo $i\n> done\n1\n2\n```\n",
+			expect:   "for i in 1 2; do\necho $i\ndone\n",
+		},
+		{
+			name:     "empty prompt",
+			contents: "```console\n$\n$ ls\n```\n",
+			expect:   "ls\n",
+		},
+		{
+			name:     "no prompts",
+			contents: "```console\nls -l | nl\n```\n",
+			expect:   "ls -l | nl\n",
+		},
+		{
+			name:     "verbatim shell block",
+			contents: "```sh\n$ date\n# comment\n```\n",
+			expect:   "$ date\n# comment\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			blocks, err := fcqs.CmdLineBlocks(strings.NewReader(tc.contents))
+
+			require.NoError(t, err)
+			require.Len(t, blocks, 1)
+			assert.Equal(t, tc.expect, blocks[0].Command)
+		})
+	}
+}